@@ -26,7 +26,16 @@ func main() {
 	key := flag.String("k", "", "webfonts key")
 	text := flag.String("text", "Lorem Ipsum Dolor", "text")
 	prefix := flag.String("prefix", "/_/", "prefix")
+	doctor := flag.Bool("doctor", false, "run diagnostics and exit")
 	flag.Parse()
+	if *doctor {
+		r := webfonts.Doctor(context.Background(), webfonts.WithKey(*key), webfonts.WithAppCacheDir("webfonts"))
+		fmt.Print(r)
+		if !r.OK() {
+			os.Exit(1)
+		}
+		return
+	}
 	if err := run(context.Background(), *verbose, *addr, *key, *text, *prefix, flag.Args()...); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
@@ -46,17 +55,17 @@ func run(ctx context.Context, verbose bool, addr, key, text, prefix string, allo
 	}
 	fmt.Printf("families: %d\n", len(families))
 	sort.Slice(families, func(i, j int) bool {
-		return families[i].Family < families[j].Family
+		return families[i].Name < families[j].Name
 	})
 	// retrieve fonts
 	var fonts []webfonts.Font
 	cl := webfonts.NewClient(webfonts.WithTransport(cache))
 	for _, font := range families {
-		if len(allowed) != 0 && !contains(allowed, font.Family) {
+		if len(allowed) != 0 && !contains(allowed, font.Name) {
 			continue
 		}
-		fmt.Printf("retrieving: %s", font.Family)
-		face, err := cl.WOFF2(ctx, font.Family, webfonts.WithDisplay("block"), webfonts.WithText(text))
+		fmt.Printf("retrieving: %s", font.Name)
+		face, err := cl.WOFF2(ctx, font.Name, webfonts.WithDisplay("block"), webfonts.WithText(text))
 		if err != nil {
 			return err
 		}
@@ -143,6 +152,7 @@ func (s *Server) build(ctx context.Context, prefix string, fonts []webfonts.Font
 			}
 			s.HandleFunc(path.Join(prefix, route.Path), func(res http.ResponseWriter, req *http.Request) {
 				res.Header().Set("Content-Type", contentType)
+				res.Header().Set("Cache-Control", webfonts.DefaultFontCacheControl)
 				_, _ = res.Write(buf)
 			})
 		}
@@ -150,6 +160,7 @@ func (s *Server) build(ctx context.Context, prefix string, fonts []webfonts.Font
 		stylesheetPath := path.Join(prefix, family) + ".css"
 		s.HandleFunc(stylesheetPath, func(res http.ResponseWriter, req *http.Request) {
 			res.Header().Set("Content-Type", "text/css")
+			res.Header().Set("Cache-Control", webfonts.DefaultStylesheetCacheControl)
 			_, _ = res.Write(buf)
 		})
 		return nil
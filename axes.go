@@ -0,0 +1,82 @@
+package webfonts
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// axesSpec builds the css2 family axes specification for the axes (ie,
+// "ital,wght@0,100..900;1,100..900").
+//
+// Axis tags are ordered with lowercase (registered) axes before uppercase
+// (custom) axes, and the value tuples are ordered numerically, as required by
+// the css2 api.
+func axesSpec(axes map[string][]string) string {
+	// sort tags
+	var tags []string
+	for tag, values := range axes {
+		if len(values) != 0 {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := isRegisteredAxis(tags[i]), isRegisteredAxis(tags[j])
+		if a != b {
+			return a
+		}
+		return tags[i] < tags[j]
+	})
+	// build tuples
+	tuples := [][]string{nil}
+	for _, tag := range tags {
+		var next [][]string
+		for _, tuple := range tuples {
+			for _, value := range axes[tag] {
+				next = append(next, append(append([]string(nil), tuple...), value))
+			}
+		}
+		tuples = next
+	}
+	sort.SliceStable(tuples, func(i, j int) bool {
+		for k := 0; k < len(tuples[i]) && k < len(tuples[j]); k++ {
+			a, b := axisValueStart(tuples[i][k]), axisValueStart(tuples[j][k])
+			if a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+	// join
+	var v []string
+	for _, tuple := range tuples {
+		v = append(v, strings.Join(tuple, ","))
+	}
+	return strings.Join(tags, ",") + "@" + strings.Join(v, ";")
+}
+
+// isRegisteredAxis returns true when the axis tag is a registered (lowercase)
+// axis.
+func isRegisteredAxis(tag string) bool {
+	for _, r := range tag {
+		if unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// axisValueStart returns the numeric start of an axis value or range.
+func axisValueStart(value string) float64 {
+	if i := strings.Index(value, ".."); i != -1 {
+		value = value[:i]
+	}
+	f, _ := strconv.ParseFloat(value, 64)
+	return f
+}
+
+// formatAxisValue formats an axis value.
+func formatAxisValue(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,442 @@
+package webfonts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kenshaw/webfonts/convert"
+)
+
+// Bundle retrieves the font faces for the specified families, writing the font
+// files and a combined stylesheet with relative urls to dir.
+func Bundle(ctx context.Context, families []string, dir string, opts ...BundleOption) error {
+	o := newBundleOptions(opts...)
+	return NewClient(o.clientOpts...).bundle(ctx, families, dir, o)
+}
+
+// Bundle retrieves the font faces for the specified families, writing the font
+// files and a combined stylesheet with relative urls to dir, producing a
+// self-hosted fonts directory, and a manifest (see Manifest) describing the
+// bundled families and font files. When formats are set (see WithFormats),
+// only the set formats are retrieved. When verification is enabled (see
+// WithVerify), each downloaded font file is verified before being written.
+// When integrity hashes are enabled (see WithIntegrity), the subresource
+// integrity manifest is written to dir.
+//
+// Failed families and font files are returned as a MultiError of
+// *FamilyError. When continuing on error (see WithContinueOnError), failed
+// families and font files are omitted from the bundle, and the bundle is
+// written before returning the error.
+//
+// Client options set with WithBundleClientOptions are not supported, as the
+// client is already built, and return ErrClientOptionsUnsupported.
+func (cl *Client) Bundle(ctx context.Context, families []string, dir string, opts ...BundleOption) error {
+	o := newBundleOptions(opts...)
+	if len(o.clientOpts) != 0 {
+		return ErrClientOptionsUnsupported
+	}
+	return cl.bundle(ctx, families, dir, o)
+}
+
+// bundle writes the bundle for the families to dir.
+func (cl *Client) bundle(ctx context.Context, families []string, dir string, o *bundleOptions) error {
+	var errs MultiError
+	// fail returns the error, or collects the error when continuing on error
+	fail := func(err error) error {
+		switch m, ok := err.(MultiError); {
+		case !cl.continueOnError:
+			return err
+		case ok:
+			errs = append(errs, m...)
+		default:
+			errs = append(errs, err)
+		}
+		return nil
+	}
+	// retrieve faces
+	res := make([][]Font, len(families))
+	if err := parallel(cl.concurrency, len(families), func(i int) error {
+		var err error
+		if o.all || cl.priority != nil {
+			res[i], err = cl.All(ctx, families[i], o.queryOpts...)
+		} else {
+			res[i], err = cl.Faces(ctx, families[i], o.queryOpts...)
+		}
+		return err
+	}); err != nil {
+		if err := fail(err); err != nil {
+			return err
+		}
+	}
+	var fonts []Font
+	for _, v := range res {
+		fonts = append(fonts, v...)
+	}
+	// add converted formats
+	conversions := make(map[string]conversion)
+	for _, font := range fonts {
+		if font.Format != FormatWOFF2 {
+			continue
+		}
+		for _, format := range o.convert {
+			f := font
+			f.Format, f.Src = format, font.Src+"#"+string(format)
+			fonts = append(fonts, f)
+			conversions[f.Src] = conversion{
+				src:    font.Src,
+				format: format,
+			}
+		}
+	}
+	srcs := make(map[string]Font, len(fonts))
+	for _, font := range fonts {
+		srcs[font.Src] = font
+	}
+	// retrieve effects
+	var effects []Effect
+	if len(families) != 0 && NewQuery(families[0], o.queryOpts...).Effects != nil {
+		var err error
+		if effects, err = cl.Effects(ctx, families[0], o.queryOpts...); err != nil {
+			if err := fail(err); err != nil {
+				return err
+			}
+		}
+	}
+	// fetch retrieves, verifies, and converts a font file
+	var mu sync.Mutex
+	var prog *progress
+	cache := make(map[string][]byte)
+	fetch := func(urlstr string) ([]byte, error) {
+		mu.Lock()
+		b, ok := cache[urlstr]
+		mu.Unlock()
+		if ok {
+			return b, nil
+		}
+		src := urlstr
+		c, isConversion := conversions[urlstr]
+		if isConversion {
+			src = c.src
+		}
+		b, err := cl.download(prog.with(ctx, srcs[urlstr].Family, srcs[urlstr].Format), src)
+		if err == nil && cl.verify {
+			err = VerifyFont(srcs[src], b)
+		}
+		if err == nil && isConversion {
+			if b, err = convert.Convert(b, string(c.format)); err != nil {
+				err = fmt.Errorf("unable to convert %s to %s: %w", c.src, c.format, err)
+			}
+		}
+		if err != nil {
+			return nil, &FamilyError{Family: srcs[urlstr].Family, Format: srcs[urlstr].Format, Err: err}
+		}
+		mu.Lock()
+		cache[urlstr] = b
+		mu.Unlock()
+		return b, nil
+	}
+	routeOpts := o.routeOpts
+	if o.inline {
+		// prefetch
+		prog = cl.newProgress(len(fonts))
+		if err := parallel(cl.concurrency, len(fonts), func(i int) error {
+			_, err := fetch(fonts[i].Src)
+			return err
+		}); err != nil {
+			if err := fail(err); err != nil {
+				return err
+			}
+			// remove failed
+			var v []Font
+			for _, font := range fonts {
+				if _, ok := cache[font.Src]; ok {
+					v = append(v, font)
+				}
+			}
+			fonts = v
+		}
+		routeOpts = append(routeOpts, WithInline(o.threshold, fetch))
+	}
+	// build stylesheet and routes
+	buf := new(bytes.Buffer)
+	var routes []Route
+	build := func() error {
+		buf.Reset()
+		routes = nil
+		return BuildRoutes("", fonts, func(_ string, stylesheet []byte, r []Route) error {
+			buf.Write(stylesheet)
+			routes = append(routes, r...)
+			return nil
+		}, routeOpts...)
+	}
+	if err := build(); err != nil {
+		return err
+	}
+	if !o.inline {
+		// prefetch
+		prog = cl.newProgress(len(routes))
+		if err := parallel(cl.concurrency, len(routes), func(i int) error {
+			_, err := fetch(routes[i].URL)
+			return err
+		}); err != nil {
+			if err := fail(err); err != nil {
+				return err
+			}
+			// remove failed, and rebuild
+			failed := make(map[string]bool)
+			for _, route := range routes {
+				if _, ok := cache[route.URL]; !ok {
+					failed[route.URL] = true
+				}
+			}
+			var v []Font
+			for _, font := range fonts {
+				if !failed[font.Src] {
+					v = append(v, font)
+				}
+			}
+			fonts = v
+			if err := build(); err != nil {
+				return err
+			}
+		}
+	}
+	for _, effect := range effects {
+		fmt.Fprintf(buf, "%s\n", effect.CSS)
+	}
+	// build variables
+	variables := make(map[VariableSyntax][]byte)
+	if len(o.variables) != 0 {
+		var names []string
+		for _, font := range fonts {
+			if !contains(names, font.Family) {
+				names = append(names, font.Family)
+			}
+		}
+		generic := func(family string) string {
+			if f, err := cl.Lookup(ctx, family); err == nil {
+				return GenericFamily(f.Category)
+			}
+			return ""
+		}
+		for _, syntax := range o.variables {
+			w := buf
+			if syntax != VariablesCSS {
+				w = new(bytes.Buffer)
+			}
+			if err := WriteVariables(w, syntax, names, generic); err != nil {
+				return err
+			}
+			if w != buf {
+				variables[syntax] = w.Bytes()
+			}
+		}
+	}
+	// write
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	written := make([]bool, len(routes))
+	files := make([]FileInfo, len(routes))
+	if err := parallel(cl.concurrency, len(routes), func(i int) error {
+		b, err := fetch(routes[i].URL)
+		if err != nil {
+			return err
+		}
+		font := srcs[routes[i].URL]
+		if c, ok := conversions[font.Src]; ok {
+			font.Src = c.src
+		}
+		files[i] = FileInfo{
+			Font:        font,
+			Path:        routes[i].Path,
+			Size:        int64(len(b)),
+			ContentType: routes[i].Format.ContentType(),
+			Integrity:   Integrity(b),
+		}
+		routes[i].ByteSize = files[i].Size
+		if cl.integrity {
+			routes[i].Integrity = files[i].Integrity
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(routes[i].Path)), b, 0o644); err != nil {
+			return err
+		}
+		written[i] = true
+		return nil
+	}); err != nil {
+		if err := fail(err); err != nil {
+			return err
+		}
+	}
+	if cl.integrity {
+		var v []Route
+		for i, route := range routes {
+			if written[i] {
+				v = append(v, route)
+			}
+		}
+		manifest, err := buildIntegrityManifest(v)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, IntegrityManifest), manifest, 0o644); err != nil {
+			return err
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, o.stylesheet), buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	for syntax, b := range variables {
+		name := strings.TrimSuffix(o.stylesheet, filepath.Ext(o.stylesheet)) + syntax.Extension()
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			return err
+		}
+	}
+	// write manifest
+	m := &Manifest{
+		Query:      NewQuery("", o.queryOpts...),
+		All:        o.all,
+		Stylesheet: o.stylesheet,
+	}
+	index := make(map[string]int)
+	for _, font := range fonts {
+		i, ok := index[font.Family]
+		if !ok {
+			i, index[font.Family] = len(m.Families), len(m.Families)
+			m.Families = append(m.Families, ManifestFamily{
+				Family: font.Family,
+				Files:  make([]FileInfo, 0),
+			})
+		}
+		for _, variant := range fontVariants(font) {
+			if !contains(m.Families[i].Variants, variant) {
+				m.Families[i].Variants = append(m.Families[i].Variants, variant)
+			}
+		}
+	}
+	for i, file := range files {
+		if j, ok := index[file.Font.Family]; ok && written[i] {
+			m.Families[j].Files = append(m.Families[j].Files, file)
+		}
+	}
+	if err := WriteManifest(dir, m); err != nil {
+		return err
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// BundleOption is a bundle option.
+type BundleOption func(*bundleOptions)
+
+// bundleOptions are bundle options.
+type bundleOptions struct {
+	clientOpts []ClientOption
+	queryOpts  []QueryOption
+	routeOpts  []RouteOption
+	stylesheet string
+	all        bool
+	convert    []Format
+	variables  []VariableSyntax
+	inline     bool
+	threshold  int64
+}
+
+// conversion is a font file conversion.
+type conversion struct {
+	src    string
+	format Format
+}
+
+// newBundleOptions builds bundle options.
+func newBundleOptions(opts ...BundleOption) *bundleOptions {
+	o := &bundleOptions{
+		stylesheet: "fonts.css",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithBundleClientOptions is a bundle option to set the client options used
+// by the package level Bundle func. Not supported by Client.Bundle.
+func WithBundleClientOptions(opts ...ClientOption) BundleOption {
+	return func(o *bundleOptions) {
+		o.clientOpts = append(o.clientOpts, opts...)
+	}
+}
+
+// WithBundleQueryOptions is a bundle option to set the query options used to
+// retrieve each family.
+func WithBundleQueryOptions(opts ...QueryOption) BundleOption {
+	return func(o *bundleOptions) {
+		o.queryOpts = append(o.queryOpts, opts...)
+	}
+}
+
+// WithBundleRouteOptions is a bundle option to set the route options used to
+// build the stylesheet.
+func WithBundleRouteOptions(opts ...RouteOption) BundleOption {
+	return func(o *bundleOptions) {
+		o.routeOpts = append(o.routeOpts, opts...)
+	}
+}
+
+// WithBundleStylesheet is a bundle option to set the stylesheet file name
+// (default: fonts.css).
+func WithBundleStylesheet(stylesheet string) BundleOption {
+	return func(o *bundleOptions) {
+		o.stylesheet = stylesheet
+	}
+}
+
+// WithBundleAllFormats is a bundle option to retrieve all common font formats
+// for each family (see Client.All). Bundles created with a client with formats
+// set (see WithFormats) always retrieve the set formats.
+func WithBundleAllFormats() BundleOption {
+	return func(o *bundleOptions) {
+		o.all = true
+	}
+}
+
+// WithBundleConvert is a bundle option to convert retrieved woff2 font files
+// to the specified formats (FormatTTF, FormatOTF, FormatWOFF), adding the converted font
+// files to the bundle. Allows a bundle to contain multiple formats retrieved
+// with a single request per family.
+func WithBundleConvert(formats ...Format) BundleOption {
+	return func(o *bundleOptions) {
+		o.convert = append(o.convert, formats...)
+	}
+}
+
+// WithBundleVariables is a bundle option to write font stack variables for
+// the bundled families using the syntaxes (see WriteVariables). css custom
+// properties are appended to the stylesheet, and scss and less variables are
+// written alongside the stylesheet (ie, fonts.scss). The generic family of
+// each font stack is determined by the family's category, when available
+// (see Client.Lookup).
+func WithBundleVariables(syntaxes ...VariableSyntax) BundleOption {
+	return func(o *bundleOptions) {
+		o.variables = append(o.variables, syntaxes...)
+	}
+}
+
+// WithBundleInline is a bundle option to inline font files with a size less
+// than or equal to the threshold in the stylesheet as base64 encoded data:
+// urls (a threshold <= 0 inlines all font files). Inlined font files are not
+// written to the bundle. Useful for single-file html exports and email
+// templates.
+func WithBundleInline(threshold int64) BundleOption {
+	return func(o *bundleOptions) {
+		o.inline, o.threshold = true, threshold
+	}
+}
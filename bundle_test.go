@@ -0,0 +1,118 @@
+package webfonts_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kenshaw/webfonts"
+	"github.com/kenshaw/webfonts/webfontstest"
+)
+
+func TestBundleContinueOnError(t *testing.T) {
+	f := webfontstest.New()
+	f.AddFamily(webfonts.Family{Name: "A", Variants: []string{"regular", "700"}})
+	f.AddStylesheet("A", "", `@font-face {
+  font-family: 'A';
+  font-style: normal;
+  font-weight: 400;
+  src: url(https://example.com/a-400.woff2) format('woff2');
+}
+@font-face {
+  font-family: 'A';
+  font-style: normal;
+  font-weight: 700;
+  src: url(https://example.com/a-700.woff2) format('woff2');
+}`)
+	f.AddFile("https://example.com/a-400.woff2", []byte("wOF2"))
+	dir := t.TempDir()
+	cl := f.Client(
+		webfonts.WithAppCacheDir(t.TempDir()),
+		webfonts.WithContinueOnError(true),
+	)
+	err := cl.Bundle(context.Background(), []string{"A"}, dir)
+	var m webfonts.MultiError
+	switch {
+	case err == nil:
+		t.Fatalf("expected error")
+	case !errors.As(err, &m) || len(m) != 1:
+		t.Fatalf("expected a single error, got: %v", err)
+	}
+	buf, err := os.ReadFile(filepath.Join(dir, "fonts.css"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	stylesheet := string(buf)
+	if !strings.Contains(stylesheet, "font-weight: 400;") {
+		t.Errorf("expected stylesheet to contain the 400 weight, got:\n%s", stylesheet)
+	}
+	if strings.Contains(stylesheet, "font-weight: 700;") {
+		t.Errorf("expected stylesheet to not contain the failed 700 weight, got:\n%s", stylesheet)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".woff2" && !strings.Contains(stylesheet, entry.Name()) {
+			t.Errorf("expected stylesheet to reference %s", entry.Name())
+		}
+	}
+}
+
+func TestBundleConvertLocalSource(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("convert", "testdata", "opensans-lightitalic.woff2"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	f := webfontstest.New()
+	f.AddFamily(webfonts.Family{Name: "A", Variants: []string{"regular"}})
+	f.AddStylesheet("A", "", `@font-face {
+  font-family: 'A';
+  font-style: normal;
+  font-weight: 400;
+  src: url(https://example.com/a-400.woff2) format('woff2');
+}`)
+	f.AddFile("https://example.com/a-400.woff2", buf)
+	dir := t.TempDir()
+	cl := f.Client(webfonts.WithAppCacheDir(t.TempDir()))
+	if err := cl.Bundle(context.Background(), []string{"A"}, dir, webfonts.WithBundleConvert(webfonts.FormatWOFF)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	local := webfonts.NewClient(webfonts.WithLocalSource(dir))
+	for _, test := range []struct {
+		format webfonts.Format
+		get    func(context.Context, string, ...webfonts.QueryOption) (webfonts.Font, error)
+		magic  string
+	}{
+		{webfonts.FormatWOFF2, local.WOFF2, "wOF2"},
+		{webfonts.FormatWOFF, local.WOFF, "wOFF"},
+	} {
+		font, err := test.get(context.Background(), "A")
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.format, err)
+		}
+		files, err := local.DownloadFonts(context.Background(), []webfonts.Font{font}, t.TempDir())
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.format, err)
+		}
+		b, err := os.ReadFile(files[0].Path)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", test.format, err)
+		}
+		if s := string(b[:4]); s != test.magic {
+			t.Errorf("%s: expected %q, got: %q", test.format, test.magic, s)
+		}
+	}
+}
+
+func TestBundleClientOptionsUnsupported(t *testing.T) {
+	cl := webfontstest.New().Client(webfonts.WithAppCacheDir(t.TempDir()))
+	err := cl.Bundle(context.Background(), []string{"A"}, t.TempDir(), webfonts.WithBundleClientOptions(webfonts.WithContinueOnError(true)))
+	if !errors.Is(err, webfonts.ErrClientOptionsUnsupported) {
+		t.Errorf("expected %v, got: %v", webfonts.ErrClientOptionsUnsupported, err)
+	}
+}
@@ -0,0 +1,55 @@
+package webfonts
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/http"
+
+	"github.com/kenshaw/diskcache"
+)
+
+// CacheKeyFunc is a func that returns the disk cache key for a request, given
+// the key derived from the request's url.
+type CacheKeyFunc func(req *http.Request, key string) string
+
+// UserAgentCacheKey is the default disk cache key func, varying the key by the
+// request's User-Agent header, when set.
+func UserAgentCacheKey(req *http.Request, key string) string {
+	if userAgent := req.Header.Get("User-Agent"); userAgent != "" {
+		return key + "@" + fmt.Sprintf("%x", md5.Sum([]byte(userAgent)))[:8]
+	}
+	return key
+}
+
+// cacheKeyMatcher is a disk cache matcher that wraps the keys of a disk
+// cache's matched policies using a cache key func.
+type cacheKeyMatcher struct {
+	cache *diskcache.Cache
+	key   CacheKeyFunc
+}
+
+// Match satisfies the diskcache.Matcher interface.
+func (m *cacheKeyMatcher) Match(req *http.Request) (string, diskcache.Policy, error) {
+	key, p, err := m.cache.Match(req)
+	if err != nil || key == "" {
+		return key, p, err
+	}
+	return m.key(req, key), p, nil
+}
+
+// newDiskCache creates a disk cache using the options, wrapping the matched
+// keys with the cache key func, if any.
+func newDiskCache(key CacheKeyFunc, opts ...diskcache.Option) (*diskcache.Cache, error) {
+	if key == nil {
+		return diskcache.New(opts...)
+	}
+	// the wrapped cache is only used to match policies
+	cache, err := diskcache.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return diskcache.New(append(opts, diskcache.WithMatchers(&cacheKeyMatcher{
+		cache: cache,
+		key:   key,
+	}))...)
+}
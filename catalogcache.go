@@ -0,0 +1,130 @@
+package webfonts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kenshaw/diskcache"
+)
+
+// catalogSnapshot is a snapshot of the available font families listed by a
+// provider.
+type catalogSnapshot struct {
+	Time     time.Time `json:"time"`
+	Families []Family  `json:"families"`
+}
+
+// list lists the available font families using the client's provider. When
+// the client has a catalog ttl (see WithCatalogTTL), listed families are
+// cached in memory, and as a json snapshot in the client's app cache dir,
+// until the ttl expires. When the provider fails, an expired snapshot is used
+// instead.
+func (cl *Client) list(ctx context.Context, o *ListOptions) ([]Family, error) {
+	if cl.catalogTTL <= 0 {
+		return cl.provider.List(ctx, cl, o)
+	}
+	key := cl.catalogKey(o)
+	cl.catalogCacheMu.Lock()
+	defer cl.catalogCacheMu.Unlock()
+	// load
+	s, ok := cl.catalogCache[key]
+	name := cl.catalogPath(key)
+	if !ok && name != "" {
+		s, ok = loadCatalogSnapshot(name)
+	}
+	if ok && time.Since(s.Time) < cl.catalogTTL {
+		return s.Families, nil
+	}
+	// retrieve
+	families, err := cl.provider.List(ctx, cl, o)
+	switch {
+	case err != nil && ok:
+		if cl.logger != nil {
+			cl.logger.Warn("using expired catalog snapshot",
+				slog.Time("time", s.Time),
+				slog.Any("error", err),
+			)
+		}
+		return s.Families, nil
+	case err != nil:
+		return nil, err
+	}
+	// store
+	s = catalogSnapshot{
+		Time:     time.Now(),
+		Families: families,
+	}
+	if cl.catalogCache == nil {
+		cl.catalogCache = make(map[string]catalogSnapshot)
+	}
+	cl.catalogCache[key] = s
+	if name != "" {
+		if err := storeCatalogSnapshot(name, s); err != nil {
+			return nil, err
+		}
+	}
+	return families, nil
+}
+
+// catalogKey returns the catalog cache key for the client's provider and the
+// list options.
+func (cl *Client) catalogKey(o *ListOptions) string {
+	buf, _ := json.Marshal(ListOptions{
+		Sort:         o.Sort,
+		Subset:       o.Subset,
+		Families:     o.Families,
+		Capabilities: o.Capabilities,
+	})
+	sum := sha256.Sum256(append([]byte(fmt.Sprintf("%T %v\n", cl.provider, cl.provider)), buf...))
+	return hex.EncodeToString(sum[:16])
+}
+
+// catalogPath returns the catalog snapshot path for the key in the client's
+// app cache dir, or an empty string when the client has no app cache dir.
+func (cl *Client) catalogPath(key string) string {
+	if cl.appCacheDir == "" {
+		return ""
+	}
+	dir, err := diskcache.UserCacheDir(cl.appCacheDir, "catalog")
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, key+".json")
+}
+
+// loadCatalogSnapshot loads a catalog snapshot.
+func loadCatalogSnapshot(name string) (catalogSnapshot, bool) {
+	var s catalogSnapshot
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return s, false
+	}
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return s, false
+	}
+	return s, true
+}
+
+// storeCatalogSnapshot atomically writes a catalog snapshot.
+func storeCatalogSnapshot(name string, s catalogSnapshot) error {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
@@ -1,19 +1,28 @@
 package webfonts
 
 import (
+	"bytes"
 	"context"
-	"crypto/md5"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/chromedp/verhist"
 	"github.com/kenshaw/diskcache"
 	"github.com/kenshaw/httplog"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/api/googleapi"
 	gtransport "google.golang.org/api/googleapi/transport"
 	"google.golang.org/api/option"
 	gfonts "google.golang.org/api/webfonts/v1"
@@ -24,21 +33,68 @@ var DefaultTransport = http.DefaultTransport
 
 // Client is a webfonts client.
 type Client struct {
-	userAgent   string
-	transport   http.RoundTripper
-	appCacheDir string
-	key         string
-	source      oauth2.TokenSource
-	opts        []option.ClientOption
-	cl          *http.Client
-	svc         *gfonts.Service
-	once        sync.Once
+	userAgent       string
+	transport       http.RoundTripper
+	base            http.RoundTripper
+	appCacheDir     string
+	fontCacheDir    string
+	cacheOpts       []diskcache.Option
+	cacheTTL        time.Duration
+	cacheKey        CacheKeyFunc
+	memoryCache     int64
+	key             string
+	source          oauth2.TokenSource
+	credentials     []string
+	opts            []option.ClientOption
+	provider        Provider
+	mirrors         []Mirror
+	failover        bool
+	concurrency     int
+	retries         int
+	recordDir       string
+	replayDir       string
+	timeout         time.Duration
+	backoff         Backoff
+	limiter         *limiter
+	verify          bool
+	continueOnError bool
+	integrity       bool
+	progress        func(ProgressEvent)
+	metrics         Collector
+	tracer          trace.Tracer
+	logger          *slog.Logger
+	localDir        string
+	local           *localSource
+	mirror          int32
+	cl              *http.Client
+	service         Service
+	initMu          sync.Mutex
+	initialized     atomic.Bool
+	closed          bool
+	userAgentMu     sync.Mutex
+	userAgentRetry  time.Time
+	userAgents      map[Format]string
+	priority        []Format
+	group           singleflight.Group
+
+	catalogTTL     time.Duration
+	catalogCacheMu sync.Mutex
+	catalogCache   map[string]catalogSnapshot
 }
 
 // NewClient creates a new webfonts client.
 func NewClient(opts ...ClientOption) *Client {
 	cl := &Client{
-		transport: DefaultTransport,
+		transport:   DefaultTransport,
+		provider:    Google,
+		mirrors:     []Mirror{MirrorGoogle},
+		concurrency: DefaultConcurrency,
+		backoff:     DefaultBackoff,
+		timeout:     DefaultRequestTimeout,
+		cacheTTL:    DefaultCacheTTL,
+		cacheKey:    UserAgentCacheKey,
+		userAgents:  DefaultUserAgents(),
+		tracer:      noopTracer,
 	}
 	for _, o := range opts {
 		o(cl)
@@ -46,38 +102,154 @@ func NewClient(opts ...ClientOption) *Client {
 	return cl
 }
 
-// init initializes the client.
+// init initializes the client. When initialization fails, the client is
+// reset, and initialization is retried on the next call.
 func (cl *Client) init(ctx context.Context) error {
-	var err error
-	cl.once.Do(func() {
-		if err = cl.buildTransport(ctx); err != nil {
-			return
-		}
-		if err = cl.buildUserAgent(ctx); err != nil {
-			return
-		}
-		if err = cl.buildService(ctx); err != nil {
-			return
+	if cl.initialized.Load() {
+		return nil
+	}
+	cl.initMu.Lock()
+	defer cl.initMu.Unlock()
+	switch {
+	case cl.closed:
+		return ErrClientClosed
+	case cl.initialized.Load():
+		return nil
+	}
+	transport := cl.transport
+	if err := cl.doInit(ctx); err != nil {
+		cl.transport, cl.base, cl.local, cl.cl = transport, nil, nil, nil
+		cl.resetService()
+		return err
+	}
+	cl.initialized.Store(true)
+	return nil
+}
+
+// doInit loads the local source, and builds the client's transport and
+// service.
+func (cl *Client) doInit(ctx context.Context) error {
+	if cl.localDir != "" {
+		var err error
+		if cl.local, err = loadLocalSource(cl.localDir); err != nil {
+			return err
 		}
-	})
-	return err
+	}
+	if err := cl.buildTransport(ctx); err != nil {
+		return err
+	}
+	return cl.buildService(ctx)
+}
+
+// Close closes the client, closing idle connections of the client's
+// underlying transport and releasing the client's service, caches, and
+// transport. Operations on a closed client return ErrClientClosed. Close
+// should not be called concurrently with in-progress operations.
+func (cl *Client) Close() error {
+	cl.initMu.Lock()
+	defer cl.initMu.Unlock()
+	if cl.closed {
+		return nil
+	}
+	if t, ok := cl.base.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+	cl.closed = true
+	cl.initialized.Store(false)
+	cl.transport, cl.base, cl.local, cl.cl = nil, nil, nil, nil
+	cl.resetService()
+	return nil
 }
 
 // buildTransport builds the http client used for retrievals.
 func (cl *Client) buildTransport(ctx context.Context) error {
-	if cl.appCacheDir != "" {
-		var err error
-		cl.transport, err = diskcache.New(
-			diskcache.WithTransport(cl.transport),
-			diskcache.WithAppCacheDir(cl.appCacheDir),
-			diskcache.WithTTL(24*time.Hour),
-			diskcache.WithHeaderWhitelist("Date", "Set-Cookie", "Content-Type", "Location"),
-			diskcache.WithErrorTruncator(),
-			diskcache.WithGzipCompression(),
-		)
+	if cl.local != nil {
+		cl.transport = &localTransport{
+			src: cl.local,
+		}
+		cl.base, cl.cl = cl.transport, &http.Client{
+			Transport: cl.transport,
+		}
+		return nil
+	}
+	switch {
+	case cl.replayDir != "":
+		cl.transport = &replayTransport{
+			dir: cl.replayDir,
+		}
+	case cl.recordDir != "":
+		cl.transport = &recordTransport{
+			dir:       cl.recordDir,
+			transport: cl.transport,
+		}
+	}
+	cl.base = cl.transport
+	if cl.timeout > 0 {
+		cl.transport = &timeoutTransport{
+			transport: cl.transport,
+			timeout:   cl.timeout,
+		}
+	}
+	if cl.limiter != nil {
+		cl.transport = &rateLimitTransport{
+			transport: cl.transport,
+			limiter:   cl.limiter,
+		}
+	}
+	if cl.logger != nil {
+		cl.transport = &logTransport{
+			transport: cl.transport,
+			logger:    cl.logger,
+		}
+	}
+	if cl.metrics != nil {
+		cl.transport = &metricsTransport{
+			transport: cl.transport,
+			metrics:   cl.metrics,
+		}
+	}
+	cl.transport = &networkTransport{
+		transport: cl.transport,
+	}
+	if cl.retries > 0 {
+		cl.transport = &retryTransport{
+			transport: cl.transport,
+			retries:   cl.retries,
+			backoff:   cl.backoff,
+		}
+	}
+	fetch := cl.transport
+	if cl.appCacheDir != "" || cl.cacheOpts != nil {
+		revalidate := &revalidateTransport{
+			transport: cl.transport,
+		}
+		opts := []diskcache.Option{
+			diskcache.WithTransport(revalidate),
+			diskcache.WithTTL(cl.cacheTTL),
+		}
+		if cl.appCacheDir != "" {
+			opts = append(opts, diskcache.WithAppCacheDir(cl.appCacheDir))
+		}
+		if cl.cacheOpts != nil {
+			opts = append(opts, cl.cacheOpts...)
+		} else {
+			opts = append(opts, DefaultCacheOptions()...)
+		}
+		cache, err := newDiskCache(cl.cacheKey, opts...)
 		if err != nil {
 			return err
 		}
+		cl.transport, revalidate.cache = cache, cache
+	}
+	if cl.memoryCache > 0 {
+		cl.transport = newMemoryCacheTransport(cl.transport, cl.memoryCache, cl.cacheTTL)
+	}
+	if cl.fontCacheDir != "" {
+		cl.transport = &fontCacheTransport{
+			dir:       cl.fontCacheDir,
+			transport: cl.transport,
+			fetch:     fetch,
+		}
 	}
 	cl.cl = &http.Client{
 		Transport: cl.transport,
@@ -85,21 +257,19 @@ func (cl *Client) buildTransport(ctx context.Context) error {
 	return nil
 }
 
-// buildUserAgent builds the user agent.
-func (cl *Client) buildUserAgent(ctx context.Context) error {
-	if cl.userAgent != "" {
-		return nil
-	}
-	var err error
-	cl.userAgent, err = verhist.UserAgent(ctx, "linux", "stable", verhist.WithTransport(cl.transport))
-	return err
-}
-
 // buildService builds the google webfonts service.
 func (cl *Client) buildService(ctx context.Context) error {
-	if cl.svc != nil {
+	if cl.service != nil {
 		return nil
 	}
+	// discover application default credentials
+	if cl.credentials != nil && cl.key == "" && cl.source == nil {
+		creds, err := google.FindDefaultCredentials(ctx, cl.credentials...)
+		if err != nil {
+			return fmt.Errorf("unable to find default credentials: %w", err)
+		}
+		cl.source = creds.TokenSource
+	}
 	// build transport
 	transport := cl.transport
 	switch {
@@ -118,58 +288,263 @@ func (cl *Client) buildService(ctx context.Context) error {
 	opts := append(cl.opts, option.WithHTTPClient(&http.Client{
 		Transport: transport,
 	}))
-	var err error
-	cl.svc, err = gfonts.NewService(ctx, opts...)
-	return err
+	svc, err := gfonts.NewService(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	cl.service = googleService{svc: svc}
+	return nil
+}
+
+// resetService resets the service built by buildService, retaining any
+// service set with WithService.
+func (cl *Client) resetService() {
+	if _, ok := cl.service.(googleService); ok {
+		cl.service = nil
+	}
 }
 
-// Available retrieves all available webfonts from the google webfonts service.
-func (cl *Client) Available(ctx context.Context) ([]*gfonts.Webfont, error) {
+// Available retrieves all available font families from the client's provider
+// (default: the google webfonts service), including variable font axes and
+// files when requested (see WithCapability).
+func (cl *Client) Available(ctx context.Context, opts ...AvailableOption) (_ []Family, err error) {
+	ctx, span := cl.startSpan(ctx, "Available")
+	defer endSpan(span, &err)
 	// init
 	if err := cl.init(ctx); err != nil {
 		return nil, err
 	}
-	if cl.svc == nil {
-		return nil, ErrServiceUninitialized
-	}
 	// retrieve
-	res, err := cl.svc.Webfonts.List().Context(ctx).Do()
+	o := newAvailableOptions(opts...)
+	var families []Family
+	if cl.local != nil {
+		families = cl.local.available(o)
+	} else {
+		var err error
+		if families, err = cl.list(ctx, o); err != nil {
+			return nil, err
+		}
+		families = cl.rank(families, o)
+	}
+	// filter
+	if len(o.Categories) == 0 {
+		return families, nil
+	}
+	var v []Family
+	for _, f := range families {
+		if contains(o.Categories, f.Category) {
+			v = append(v, f)
+		}
+	}
+	return v, nil
+}
+
+// rank sets the popularity or trending rank of the families when the
+// families are sorted by popularity or trending, and were not filtered by
+// family or subset.
+func (cl *Client) rank(families []Family, o *ListOptions) []Family {
+	if cl.provider != Google || len(o.Families) != 0 || o.Subset != "" {
+		return families
+	}
+	switch o.Sort {
+	case SortPopularity, SortTrending:
+	default:
+		return families
+	}
+	families = append([]Family(nil), families...)
+	for i := range families {
+		if o.Sort == SortPopularity {
+			families[i].PopularityRank = i + 1
+		} else {
+			families[i].TrendingRank = i + 1
+		}
+	}
+	return families
+}
+
+// Popular retrieves the n most popular available font families (see
+// WithSort), with their popularity rank set. When n <= 0, all available
+// families are returned.
+func (cl *Client) Popular(ctx context.Context, n int, opts ...AvailableOption) ([]Family, error) {
+	families, err := cl.Available(ctx, append(opts, WithSort(SortPopularity))...)
 	if err != nil {
 		return nil, err
 	}
-	return res.Items, nil
+	if 0 < n && n < len(families) {
+		families = families[:n]
+	}
+	return families, nil
 }
 
-// get retrieves a stylesheet from the url using the specified user agent,
+// get retrieves a stylesheet for the query using the specified user agent,
 // return any parsed font faces contained in the stylesheet.
+func (cl *Client) get(ctx context.Context, q *Query, userAgent string) (_ []Font, err error) {
+	families := q.Families
+	if q.Family != "" {
+		families = append([]string{q.Family}, families...)
+	}
+	ctx, span := cl.startSpan(ctx, "stylesheet",
+		attrFamily.StringSlice(families),
+		attrFormat.String(string(cl.userAgentFormat(userAgent))),
+	)
+	defer endSpan(span, &err)
+	if cl.local != nil {
+		return cl.local.get(q, cl.userAgentFormat(userAgent))
+	}
+	// retrieve
+	buf, p, err := cl.retrieve(ctx, q, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	return cl.parse(q, buf, p)
+}
+
+// parse parses the font faces in the stylesheet retrieved for the query.
+func (cl *Client) parse(q *Query, buf []byte, p *Provenance) ([]Font, error) {
+	fonts, err := FontsFromStylesheetReader(bytes.NewReader(buf))
+	cl.logParse(p, fonts, err)
+	if err != nil {
+		if cl.metrics != nil {
+			cl.metrics.ParseError()
+		}
+		return nil, fmt.Errorf("%s: %w", p.URL, err)
+	}
+	for i := range fonts {
+		if err := cl.resolve(p.URL, &fonts[i]); err != nil {
+			return nil, err
+		}
+		fonts[i].Provenance = p
+		fonts[i].Axes = q.Axes
+	}
+	return fonts, nil
+}
+
+// resolve resolves the font's source urls relative to the stylesheet url
+// using the client's provider.
+func (cl *Client) resolve(base string, font *Font) error {
+	for i, source := range font.Sources {
+		if source.URL == "" {
+			continue
+		}
+		urlstr, err := cl.provider.Resolve(base, source.URL)
+		if err != nil {
+			return err
+		}
+		if font.Sources[i].URL = urlstr; source.URL == font.Src {
+			font.Src = urlstr
+		}
+	}
+	return nil
+}
+
+// HTTPClient returns the client's http client, initializing the client if
+// necessary. Used by providers to make requests using the client's transport.
+func (cl *Client) HTTPClient(ctx context.Context) (*http.Client, error) {
+	if err := cl.init(ctx); err != nil {
+		return nil, err
+	}
+	if cl.cl == nil {
+		return nil, ErrClientUninitialized
+	}
+	return cl.cl, nil
+}
+
+// retrieve retrieves a stylesheet for the query using the specified user
+// agent, returning the stylesheet and its provenance.
+//
+// When the client has failover enabled, each of the provider's stylesheet urls
+// (for google, one for each of the client's mirrors) will be tried in order
+// until a url is reachable. The last reachable position is used first for
+// subsequent requests.
+func (cl *Client) retrieve(ctx context.Context, q *Query, userAgent string) ([]byte, *Provenance, error) {
+	if cl.local != nil {
+		return nil, nil, ErrNotAvailableOffline
+	}
+	urls := cl.provider.Stylesheet(cl, q)
+	var err error
+	for _, i := range cl.mirrorOrder(len(urls)) {
+		var buf []byte
+		var p *Provenance
+		buf, p, err = cl.fetch(ctx, urls[i], userAgent)
+		if err == nil {
+			cl.setMirror(i)
+			return buf, p, nil
+		}
+		var unreachable bool
+		if err, unreachable = unwrapUnreachable(err); !cl.failover || !unreachable || ctx.Err() != nil {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, err
+}
+
+// sharedTimeout returns the timeout for requests shared by concurrent callers
+// (see share), allowing the request timeout for each retry.
+func (cl *Client) sharedTimeout() time.Duration {
+	return cl.timeout * time.Duration(cl.retries+1)
+}
+
+// fetch retrieves a stylesheet from the url using the specified user agent,
+// returning the stylesheet and its provenance. Concurrent fetches of the same
+// url and user agent are deduplicated (see share).
 //
-// Adds &_=<md5hash(userAgent)[:5]> to the query request to ensure request
-// traverses transport caching.
-func (cl *Client) get(ctx context.Context, urlstr, userAgent string) ([]Font, error) {
+// Cached stylesheets vary by the user agent (see WithCacheKeyFunc).
+func (cl *Client) fetch(ctx context.Context, urlstr, userAgent string) ([]byte, *Provenance, error) {
+	type result struct {
+		buf []byte
+		p   *Provenance
+	}
+	v, err := share(ctx, &cl.group, "fetch\x00"+urlstr+"\x00"+userAgent, cl.sharedTimeout(), func(ctx context.Context) (interface{}, error) {
+		buf, p, err := cl.doFetch(ctx, urlstr, userAgent)
+		return result{buf, p}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	r := v.(result)
+	return r.buf, r.p, nil
+}
+
+// doFetch retrieves a stylesheet from the url using the specified user agent.
+func (cl *Client) doFetch(ctx context.Context, urlstr, userAgent string) ([]byte, *Provenance, error) {
 	// build request
-	urlstr += "&_=" + fmt.Sprintf("%x", md5.Sum([]byte(userAgent)))[:5]
 	req, err := http.NewRequest("GET", urlstr, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.Header.Set("User-Agent", userAgent)
 	// execute
-	res, err := cl.cl.Do(req.WithContext(ctx))
+	p := &Provenance{
+		URL:    urlstr,
+		Cached: true,
+	}
+	res, err := cl.cl.Do(req.WithContext(withProvenance(ctx, p)))
 	if err != nil {
-		return nil, err
+		return nil, nil, &unreachableError{err: err}
 	}
 	defer res.Body.Close()
 	// check status
-	if res.StatusCode != http.StatusOK {
-		return nil, ErrStatusNotOK
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		return nil, nil, &unreachableError{err: newStatusError(urlstr, res)}
+	case res.StatusCode != http.StatusOK:
+		return nil, nil, newStatusError(urlstr, res)
+	}
+	// read
+	buf, err := ioutil.ReadAll(progressBody(ctx, urlstr, res.Body, res.ContentLength))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", urlstr, err)
 	}
-	// parse
-	return FontsFromStylesheetReader(res.Body)
+	cl.collect(KindStylesheet, p, len(buf))
+	return buf, buildProvenance(p, res), nil
 }
 
 // Faces retrieves the font faces for the specified family, building a query
-// using the client's user agent and passed options.
-func (cl *Client) Faces(ctx context.Context, family string, opts ...QueryOption) ([]Font, error) {
+// using the client's user agent and passed options. Failures are returned as
+// a *FamilyError.
+func (cl *Client) Faces(ctx context.Context, family string, opts ...QueryOption) (_ []Font, err error) {
+	ctx, span := cl.startSpan(ctx, "Faces", attrFamily.String(family))
+	defer endSpan(span, &err)
 	// initialize
 	if err := cl.init(ctx); err != nil {
 		return nil, err
@@ -179,17 +554,125 @@ func (cl *Client) Faces(ctx context.Context, family string, opts ...QueryOption)
 	}
 	// build query
 	q := NewQuery(family, opts...)
-	userAgent := cl.userAgent
-	if q.UserAgent != "" {
-		userAgent = q.UserAgent
+	userAgent := cl.queryUserAgent(ctx, q)
+	// retrieve
+	fonts, err := cl.get(ctx, q, userAgent)
+	if err != nil {
+		return nil, &FamilyError{Family: family, Err: err}
+	}
+	return fonts, nil
+}
+
+// FacesMulti retrieves the font faces for the specified families, building
+// queries using the client's user agent and passed options, returning the
+// font faces keyed by family.
+//
+// Multiple families are retrieved with a single stylesheet request, with at
+// most MaxFacesMulti families per request. Requests are made concurrently
+// (see WithConcurrency).
+//
+// Failed requests are returned as a MultiError of *FamilyError for each
+// family in the request. When continuing on error (see WithContinueOnError),
+// the font faces successfully retrieved are returned along with the error.
+func (cl *Client) FacesMulti(ctx context.Context, families []string, opts ...QueryOption) (map[string][]Font, error) {
+	// initialize
+	if err := cl.init(ctx); err != nil {
+		return nil, err
+	}
+	if cl.cl == nil {
+		return nil, ErrClientUninitialized
+	}
+	// build queries
+	var queries []*Query
+	for i := 0; i < len(families); i += MaxFacesMulti {
+		q := NewQuery(families[i], opts...)
+		q.Families = families[i:minInt(i+MaxFacesMulti, len(families))]
+		queries = append(queries, q)
+	}
+	// retrieve
+	res := make([][]Font, len(queries))
+	err := parallel(cl.concurrency, len(queries), func(i int) error {
+		userAgent := cl.queryUserAgent(ctx, queries[i])
+		var err error
+		if res[i], err = cl.get(ctx, queries[i], userAgent); err != nil {
+			var errs MultiError
+			for _, family := range queries[i].Families {
+				errs = append(errs, &FamilyError{Family: family, Err: err})
+			}
+			return errs
+		}
+		return nil
+	})
+	if err != nil && !cl.continueOnError {
+		return nil, err
+	}
+	// arrange by requested family
+	m := make(map[string][]Font, len(families))
+	for _, family := range families {
+		m[family] = nil
+	}
+	for _, fonts := range res {
+		for _, font := range fonts {
+			family := font.Family
+			for _, s := range families {
+				if strings.EqualFold(s, family) {
+					family = s
+					break
+				}
+			}
+			m[family] = append(m[family], font)
+		}
+	}
+	return m, err
+}
+
+// MaxFacesMulti is the maximum number of families retrieved in a single
+// stylesheet request by FacesMulti.
+var MaxFacesMulti = 25
+
+// Stylesheet retrieves the stylesheet for the specified family, building a
+// query using the client's user agent and passed options, returning the raw
+// stylesheet and its parsed font faces. Failures are returned as a
+// *FamilyError.
+func (cl *Client) Stylesheet(ctx context.Context, family string, opts ...QueryOption) ([]byte, []Font, error) {
+	// initialize
+	if err := cl.init(ctx); err != nil {
+		return nil, nil, err
 	}
+	if cl.cl == nil {
+		return nil, nil, ErrClientUninitialized
+	}
+	// build query
+	q := NewQuery(family, opts...)
+	userAgent := cl.queryUserAgent(ctx, q)
 	// retrieve
-	return cl.get(ctx, q.String(), userAgent)
+	buf, p, err := cl.retrieve(ctx, q, userAgent)
+	if err != nil {
+		return nil, nil, &FamilyError{Family: family, Err: err}
+	}
+	fonts, err := cl.parse(q, buf, p)
+	if err != nil {
+		return nil, nil, &FamilyError{Family: family, URL: p.URL, Err: err}
+	}
+	return buf, fonts, nil
 }
 
 // All retrieves all common font faces for the specified family by using
-// multiple user agents (EOT, SVG, TTF, WOFF2, WOFF).
-func (cl *Client) All(ctx context.Context, family string, opts ...QueryOption) ([]Font, error) {
+// multiple user agents (EOT, SVG, TTF, WOFF2, WOFF, see WithUserAgents), or
+// only the formats set with WithFormats. OTF font faces are only retrieved
+// when set with WithFormats. The user agent requests are made
+// concurrently (see WithConcurrency). Font faces in a format retrieved by
+// another format's user agent (ie, ttf font faces served for the otf user
+// agent), or not in the formats set with WithFormats, are omitted.
+// Duplicate font faces served for multiple user agents are removed (see
+// DedupeFonts).
+//
+// Failed requests are returned as a MultiError of *FamilyError. When
+// continuing on error (see WithContinueOnError), the font faces successfully
+// retrieved are returned along with the error.
+func (cl *Client) All(ctx context.Context, family string, opts ...QueryOption) (_ []Font, err error) {
+	ctx, span := cl.startSpan(ctx, "All", attrFamily.String(family))
+	defer endSpan(span, &err)
 	// initialize
 	if err := cl.init(ctx); err != nil {
 		return nil, err
@@ -199,25 +682,66 @@ func (cl *Client) All(ctx context.Context, family string, opts ...QueryOption) (
 	}
 	// build query
 	q := NewQuery(family, opts...)
+	formats := cl.formats()
+	userAgents := make([]string, len(formats))
+	for i, format := range formats {
+		userAgents[i] = cl.userAgents[format]
+	}
+	// retrieve
+	prog := cl.newProgress(len(userAgents))
+	res := make([][]Font, len(userAgents))
+	err = parallel(cl.concurrency, len(userAgents), func(i int) error {
+		var err error
+		if res[i], err = cl.get(prog.with(ctx, family, formats[i]), q, userAgents[i]); err != nil {
+			return &FamilyError{Family: family, Format: formats[i], Err: err}
+		}
+		return nil
+	})
+	if err != nil && !cl.continueOnError {
+		return nil, err
+	}
 	var faces []Font
-	for _, userAgent := range []string{
-		UserAgentEOT,
-		UserAgentSVG,
-		UserAgentTTF,
-		UserAgentWOFF2,
-		UserAgentWOFF,
-	} {
-		fonts, err := cl.get(ctx, q.String(), userAgent)
-		if err != nil {
-			return nil, err
+	for i, fonts := range res {
+		for _, font := range fonts {
+			if font.Format != formats[i] && (cl.priority != nil || containsFormat(formats, font.Format)) {
+				continue
+			}
+			faces = append(faces, font)
 		}
-		faces = append(faces, fonts...)
 	}
-	return faces, nil
+	return DedupeFonts(faces), err
+}
+
+// Effects retrieves the font effect rules for the specified family, building a
+// query using the client's user agent and passed options. Effects are only
+// returned when the query includes effects (see WithEffects). Failures are
+// returned as a *FamilyError.
+func (cl *Client) Effects(ctx context.Context, family string, opts ...QueryOption) ([]Effect, error) {
+	// initialize
+	if err := cl.init(ctx); err != nil {
+		return nil, err
+	}
+	if cl.cl == nil {
+		return nil, ErrClientUninitialized
+	}
+	// build query
+	q := NewQuery(family, opts...)
+	userAgent := cl.queryUserAgent(ctx, q)
+	// retrieve
+	buf, p, err := cl.retrieve(ctx, q, userAgent)
+	if err != nil {
+		return nil, &FamilyError{Family: family, Err: err}
+	}
+	effects, err := EffectsFromStylesheetReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, &FamilyError{Family: family, URL: p.URL, Err: err}
+	}
+	return effects, nil
 }
 
 // Format retrieves a font face with the specified format and family.
-func (cl *Client) Format(ctx context.Context, family, format string, opts ...QueryOption) (Font, error) {
+// Failures are returned as a *FamilyError.
+func (cl *Client) Format(ctx context.Context, family string, format Format, opts ...QueryOption) (Font, error) {
 	// initialize
 	if err := cl.init(ctx); err != nil {
 		return Font{}, err
@@ -225,70 +749,87 @@ func (cl *Client) Format(ctx context.Context, family, format string, opts ...Que
 	if cl.cl == nil {
 		return Font{}, ErrClientUninitialized
 	}
-	var userAgent string
-	switch format {
-	case "eot":
-		userAgent = UserAgentEOT
-	case "svg":
-		userAgent = UserAgentSVG
-	case "ttf":
-		userAgent = UserAgentTTF
-	case "woff2":
-		userAgent = UserAgentWOFF2
-	case "woff":
-		userAgent = UserAgentWOFF
-	default:
-		return Font{}, ErrFormatNotAvailable
+	userAgent, ok := cl.userAgents[format]
+	if !ok {
+		return Font{}, &FamilyError{Family: family, Format: format, Err: ErrFormatNotAvailable}
 	}
 	// build query
-	fonts, err := cl.get(ctx, NewQuery(family, opts...).String(), userAgent)
+	fonts, err := cl.get(ctx, NewQuery(family, opts...), userAgent)
 	if err != nil {
-		return Font{}, nil
+		return Font{}, &FamilyError{Family: family, Format: format, Err: err}
 	}
 	for _, font := range fonts {
 		if font.Format == format {
 			return font, nil
 		}
 	}
-	return Font{}, ErrFormatNotAvailable
+	return Font{}, &FamilyError{Family: family, Format: format, Err: ErrFormatNotAvailable}
+}
+
+// Best retrieves the font face for the specified family in the first
+// available format of the preferred formats (ie, woff2, woff, ttf). Formats
+// that are not available are skipped, and other failures are returned
+// immediately. Failures are returned as a *FamilyError.
+func (cl *Client) Best(ctx context.Context, family string, preference []Format, opts ...QueryOption) (Font, error) {
+	for _, format := range preference {
+		switch font, err := cl.Format(ctx, family, format, opts...); {
+		case err == nil:
+			return font, nil
+		case !errors.Is(err, ErrFormatNotAvailable):
+			return Font{}, err
+		}
+	}
+	return Font{}, &FamilyError{Family: family, Err: ErrFormatNotAvailable}
 }
 
 // EOT retrieves the eot font face for the specified family.
 func (cl *Client) EOT(ctx context.Context, family string, opts ...QueryOption) (Font, error) {
-	return cl.Format(ctx, family, "eot", opts...)
+	return cl.Format(ctx, family, FormatEOT, opts...)
 }
 
 // SVG retrieves the svg font face for the specified family.
 func (cl *Client) SVG(ctx context.Context, family string, opts ...QueryOption) (Font, error) {
-	return cl.Format(ctx, family, "svg", opts...)
+	return cl.Format(ctx, family, FormatSVG, opts...)
 }
 
 // TTF retrieves the ttf font face for the specified family.
 func (cl *Client) TTF(ctx context.Context, family string, opts ...QueryOption) (Font, error) {
-	return cl.Format(ctx, family, "ttf", opts...)
+	return cl.Format(ctx, family, FormatTTF, opts...)
+}
+
+// OTF retrieves the otf font face for the specified family. Only providers
+// serving otf font files return an otf font face, as Google Fonts serves ttf
+// font files to the otf user agent (see WithBundleConvert to derive otf font
+// files for cff based fonts).
+func (cl *Client) OTF(ctx context.Context, family string, opts ...QueryOption) (Font, error) {
+	return cl.Format(ctx, family, FormatOTF, opts...)
 }
 
 // WOFF2 retrieves the woff2 font face for the specified family.
 func (cl *Client) WOFF2(ctx context.Context, family string, opts ...QueryOption) (Font, error) {
-	return cl.Format(ctx, family, "woff2", opts...)
+	return cl.Format(ctx, family, FormatWOFF2, opts...)
 }
 
 // WOFF retrieves the woff font face for the specified family.
 func (cl *Client) WOFF(ctx context.Context, family string, opts ...QueryOption) (Font, error) {
-	return cl.Format(ctx, family, "woff", opts...)
+	return cl.Format(ctx, family, FormatWOFF, opts...)
 }
 
 // Query wraps a font request.
 type Query struct {
-	Family    string
-	UserAgent string
-	Variants  []string
-	Subsets   []string
-	Styles    []string
-	Effects   []string
-	Directory string
-	Display   string
-	Text      string
+	Family    string              `json:"family,omitempty"`
+	UserAgent string              `json:"userAgent,omitempty"`
+	Variants  []string            `json:"variants,omitempty"`
+	Subsets   []string            `json:"subsets,omitempty"`
+	Styles    []string            `json:"styles,omitempty"`
+	Effects   []string            `json:"effects,omitempty"`
+	Directory string              `json:"directory,omitempty"`
+	Display   string              `json:"display,omitempty"`
+	Text      string              `json:"text,omitempty"`
+	Axes      map[string][]string `json:"axes,omitempty"`
+	// Families are the families to retrieve in a single request. When
+	// empty, only Family is retrieved.
+	Families []string `json:"families,omitempty"`
 }
 
 // NewQuery builds a new webfont query.
@@ -304,12 +845,27 @@ func NewQuery(family string, opts ...QueryOption) *Query {
 
 // Values returns the url values for the request.
 func (q *Query) Values() url.Values {
-	family := q.Family
-	if q.Variants != nil {
-		family += ":" + strings.Join(q.Variants, ",")
+	families := q.Families
+	if len(families) == 0 {
+		families = []string{q.Family}
+	}
+	specs := make([]string, len(families))
+	for i, family := range families {
+		switch {
+		case len(q.Axes) != 0:
+			family += ":" + axesSpec(q.Axes)
+		case q.Variants != nil:
+			family += ":" + strings.Join(q.Variants, ",")
+		}
+		specs[i] = family
 	}
+	// the css2 api uses a family parameter per family, the css api uses a
+	// single |-separated family parameter
 	v := url.Values{
-		"family": []string{family},
+		"family": specs,
+	}
+	if len(q.Axes) == 0 {
+		v["family"] = []string{strings.Join(specs, "|")}
 	}
 	if q.Subsets != nil {
 		v["subset"] = []string{strings.Join(q.Subsets, ",")}
@@ -333,7 +889,17 @@ func (q *Query) Values() url.Values {
 //
 // Returns the URL for the request.
 func (q *Query) String() string {
-	return "https://fonts.googleapis.com/css?" + q.Values().Encode()
+	return q.URL(string(MirrorGoogle))
+}
+
+// URL returns the URL for the request using the specified endpoint. Queries
+// with variable font axes use the css2 api.
+func (q *Query) URL(endpoint string) string {
+	path := "/css?"
+	if len(q.Axes) != 0 {
+		path = "/css2?"
+	}
+	return strings.TrimSuffix(endpoint, "/") + path + q.Values().Encode()
 }
 
 // ClientOption is a webfonts client option.
@@ -354,6 +920,16 @@ func WithLogf(logf interface{}, opts ...httplog.Option) ClientOption {
 	}
 }
 
+// WithLogger is a webfonts client option to set a structured logger for
+// upstream requests (debug, or warn on failure), retrieved stylesheets and
+// font files including cache behavior (debug), and stylesheet parse failures
+// and warnings. Can be used alongside WithLogf.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(cl *Client) {
+		cl.logger = logger
+	}
+}
+
 // WithAppCacheDir is a webfonts client option to set the app cache dir.
 func WithAppCacheDir(appCacheDir string) ClientOption {
 	return func(cl *Client) {
@@ -361,6 +937,70 @@ func WithAppCacheDir(appCacheDir string) ClientOption {
 	}
 }
 
+// WithCatalogTTL is a webfonts client option to cache the available font
+// families (see Available) for the ttl, in memory and as a json snapshot in
+// the app cache dir (see WithAppCacheDir), so that repeated invocations and
+// restarts do not use Developer API quota. When the Developer API fails, an
+// expired snapshot is used. The Google Fonts catalog typically changes at
+// most daily.
+func WithCatalogTTL(ttl time.Duration) ClientOption {
+	return func(cl *Client) {
+		cl.catalogTTL = ttl
+	}
+}
+
+// WithMemoryCache is a webfonts client option to cache responses in memory,
+// retaining at most maxBytes of response bodies and evicting the least
+// recently used responses. Responses expire after the cache ttl (see
+// WithCacheTTL).
+//
+// Useful as an alternative to the disk cache (see WithAppCacheDir) in
+// environments without a writable disk.
+func WithMemoryCache(maxBytes int64) ClientOption {
+	return func(cl *Client) {
+		cl.memoryCache = maxBytes
+	}
+}
+
+// WithCacheOptions is a webfonts client option to set the disk cache options,
+// replacing the default disk cache options (see DefaultCacheOptions). Enables
+// the disk cache when no app cache dir has been set (see WithAppCacheDir).
+func WithCacheOptions(opts ...diskcache.Option) ClientOption {
+	return func(cl *Client) {
+		cl.cacheOpts = append(make([]diskcache.Option, 0, len(opts)), opts...)
+	}
+}
+
+// WithCacheTTL is a webfonts client option to set the disk cache ttl
+// (default: DefaultCacheTTL). Stale cached responses with an ETag or
+// Last-Modified header are revalidated using a conditional request.
+func WithCacheTTL(ttl time.Duration) ClientOption {
+	return func(cl *Client) {
+		cl.cacheTTL = ttl
+	}
+}
+
+// WithCacheKeyFunc is a webfonts client option to set the func used to build
+// disk cache keys (default: UserAgentCacheKey). A nil func uses keys derived
+// only from the request url.
+func WithCacheKeyFunc(f CacheKeyFunc) ClientOption {
+	return func(cl *Client) {
+		cl.cacheKey = f
+	}
+}
+
+// WithFontCacheDir is a webfonts client option to set the font cache dir.
+//
+// Font files retrieved from immutable hosts (see FontCacheHosts) are stored
+// in the font cache dir without expiration, bypassing the app cache (see
+// WithAppCacheDir), which continues to be used for stylesheets and other
+// requests.
+func WithFontCacheDir(fontCacheDir string) ClientOption {
+	return func(cl *Client) {
+		cl.fontCacheDir = fontCacheDir
+	}
+}
+
 // WithClientOption is a webfonts client option to set underlying client
 // options.
 func WithClientOption(opt option.ClientOption) ClientOption {
@@ -376,6 +1016,36 @@ func WithKey(key string) ClientOption {
 	}
 }
 
+// WithKeyFromEnv is a webfonts client option to set the google webfonts api
+// key from the environment variable (ie, GOOGLE_FONTS_API_KEY). The api key
+// is not changed when the environment variable is not set.
+func WithKeyFromEnv(name string) ClientOption {
+	return func(cl *Client) {
+		if key := os.Getenv(name); key != "" {
+			cl.key = key
+		}
+	}
+}
+
+// WithDefaultCredentials is a webfonts client option to use application
+// default credentials (see golang.org/x/oauth2/google.FindDefaultCredentials)
+// for the google webfonts api when no api key or token source is set,
+// requesting the scopes (default: DefaultCredentialsScope). Allows server
+// deployments to use the environment's credentials (ie, a service account)
+// without an api key.
+func WithDefaultCredentials(scopes ...string) ClientOption {
+	return func(cl *Client) {
+		if len(scopes) == 0 {
+			scopes = []string{DefaultCredentialsScope}
+		}
+		cl.credentials = scopes
+	}
+}
+
+// DefaultCredentialsScope is the default scope requested for application
+// default credentials (see WithDefaultCredentials).
+const DefaultCredentialsScope = "https://www.googleapis.com/auth/cloud-platform"
+
 // WithTokenSource is a webfonts client option to set the token source.
 func WithTokenSource(source oauth2.TokenSource) ClientOption {
 	return func(cl *Client) {
@@ -383,6 +1053,301 @@ func WithTokenSource(source oauth2.TokenSource) ClientOption {
 	}
 }
 
+// WithProvider is a webfonts client option to set the font provider (see
+// Google, Bunny).
+func WithProvider(provider Provider) ClientOption {
+	return func(cl *Client) {
+		cl.provider = provider
+	}
+}
+
+// WithRecord is a webfonts client option to record the responses of all
+// upstream requests (Developer API, stylesheet, font file, and user agent
+// requests) to golden files in dir, for later replay (see WithReplay). Golden
+// files are keyed by the request's method, url, and user agent. Api keys are
+// not recorded.
+func WithRecord(dir string) ClientOption {
+	return func(cl *Client) {
+		cl.recordDir = dir
+	}
+}
+
+// WithReplay is a webfonts client option to replay responses recorded in dir
+// (see WithRecord) instead of making upstream requests, making tests
+// hermetic. Requests without a recorded response fail with ErrNotRecorded.
+func WithReplay(dir string) ClientOption {
+	return func(cl *Client) {
+		cl.replayDir = dir
+	}
+}
+
+// WithService is a webfonts client option to set the Google Fonts Developer
+// API service used by the google provider to list the available font
+// families, instead of the Developer API (see the webfontstest package for a
+// test double).
+func WithService(service Service) ClientOption {
+	return func(cl *Client) {
+		cl.service = service
+	}
+}
+
+// WithMirrors is a webfonts client option to set the stylesheet mirrors used
+// for retrievals. Unless failover is enabled, only the first mirror is used.
+func WithMirrors(mirrors ...Mirror) ClientOption {
+	return func(cl *Client) {
+		if len(mirrors) != 0 {
+			cl.mirrors = mirrors
+		}
+	}
+}
+
+// WithFailover is a webfonts client option to enable automatic failover to
+// the client's next mirror when a mirror is unreachable. When no mirrors are
+// specified, uses the built-in mirror presets (see Mirrors).
+func WithFailover() ClientOption {
+	return func(cl *Client) {
+		cl.failover = true
+		if len(cl.mirrors) == 1 && cl.mirrors[0] == MirrorGoogle {
+			cl.mirrors = Mirrors()
+		}
+	}
+}
+
+// WithConcurrency is a webfonts client option to set the maximum number of
+// concurrent requests.
+func WithConcurrency(concurrency int) ClientOption {
+	return func(cl *Client) {
+		cl.concurrency = concurrency
+	}
+}
+
+// WithRetries is a webfonts client option to set the number of times transient
+// failures (429 and 5xx responses, and transport errors) are retried.
+func WithRetries(retries int) ClientOption {
+	return func(cl *Client) {
+		cl.retries = retries
+	}
+}
+
+// WithRequestTimeout is a webfonts client option to set the timeout for each
+// upstream request, including reading the response body (default:
+// DefaultRequestTimeout). Each retry (see WithRetries) has its own timeout. A
+// timeout of 0 disables the timeout, leaving requests bound only by the
+// caller's context.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(cl *Client) {
+		cl.timeout = timeout
+	}
+}
+
+// WithBackoff is a webfonts client option to set the retry backoff policy
+// (see WithRetries).
+func WithBackoff(backoff Backoff) ClientOption {
+	return func(cl *Client) {
+		cl.backoff = backoff
+	}
+}
+
+// WithVerify is a webfonts client option to verify downloaded font files
+// (see VerifyFont). Used by Download and Bundle.
+func WithVerify(verify bool) ClientOption {
+	return func(cl *Client) {
+		cl.verify = verify
+	}
+}
+
+// WithIntegrity is a webfonts client option to compute subresource integrity
+// hashes for downloaded font files, and to write a manifest of the hashes
+// (see IntegrityManifest). Used by Download, Bundle, and BuildFS.
+func WithIntegrity(integrity bool) ClientOption {
+	return func(cl *Client) {
+		cl.integrity = integrity
+	}
+}
+
+// WithRateLimit is a webfonts client option to limit the rate of requests made
+// to the network (stylesheets, font files, and the google webfonts api) to
+// rps requests per second, with bursts of at most burst requests. Requests
+// wait for the limiter, or until the request's context is done. Cached
+// responses are not limited.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(cl *Client) {
+		if rps > 0 {
+			cl.limiter = newLimiter(rps, burst)
+		} else {
+			cl.limiter = nil
+		}
+	}
+}
+
+// WithContinueOnError is a webfonts client option to continue processing the
+// remaining families and formats in bulk operations (All, FacesMulti,
+// DownloadFonts, Bundle) when a family or format fails, returning the
+// partial results along with a MultiError of *FamilyError.
+func WithContinueOnError(continueOnError bool) ClientOption {
+	return func(cl *Client) {
+		cl.continueOnError = continueOnError
+	}
+}
+
+// WithDefaultUserAgent is a webfonts client option to set the user agent used
+// for stylesheet requests, instead of resolving the current chrome user agent
+// on first use (see FallbackUserAgents). The user agent can be overridden per
+// query (see WithUserAgent).
+func WithDefaultUserAgent(userAgent string) ClientOption {
+	return func(cl *Client) {
+		cl.userAgent = userAgent
+	}
+}
+
+// WithUserAgents is a webfonts client option to set the user agents used to
+// retrieve font formats (see DefaultUserAgents), keyed by format. Allows user
+// agents to be updated when the provider changes its user agent sniffing. An
+// empty user agent removes the format.
+func WithUserAgents(userAgents map[Format]string) ClientOption {
+	return func(cl *Client) {
+		for format, userAgent := range userAgents {
+			if userAgent == "" {
+				delete(cl.userAgents, format)
+			} else {
+				cl.userAgents[format] = userAgent
+			}
+		}
+	}
+}
+
+// WithFormats is a webfonts client option to set the font formats retrieved,
+// in priority order, by bulk operations (All, Bundle), instead of all formats
+// with user agents (see WithUserAgents). Formats without a user agent are
+// skipped.
+func WithFormats(formats ...Format) ClientOption {
+	return func(cl *Client) {
+		cl.priority = formats
+	}
+}
+
+// WithLocalSource is a webfonts client option to resolve font faces and font
+// files against a previously written mirror (see Client.Mirror) or bundle
+// (see Bundle) in dir, without any network access. Retrieved font faces are
+// filtered by the query's families, variants, and subsets, and the format for
+// the user agent. Raw stylesheets and effects are not available.
+func WithLocalSource(dir string) ClientOption {
+	return func(cl *Client) {
+		cl.localDir = dir
+	}
+}
+
+// WithProgress is a webfonts client option to set a func receiving progress
+// events for the files retrieved by bulk operations (All, DownloadFonts,
+// Bundle). The func may be called concurrently.
+func WithProgress(f func(ProgressEvent)) ClientOption {
+	return func(cl *Client) {
+		cl.progress = f
+	}
+}
+
+// WithMetrics is a webfonts client option to set a collector for client
+// metrics (upstream requests, cache hits and misses, bytes downloaded, and
+// stylesheet parse failures). See the webfontsprom package for a Prometheus
+// collector.
+func WithMetrics(metrics Collector) ClientOption {
+	return func(cl *Client) {
+		cl.metrics = metrics
+	}
+}
+
+// WithTracerProvider is a webfonts client option to set the OpenTelemetry
+// tracer provider used to create spans for client operations (Available,
+// Faces, All, and stylesheet and font file retrievals). Spans carry the
+// webfonts.family and webfonts.format attributes.
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(cl *Client) {
+		cl.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// AvailableOption is an option for retrieving the available webfonts.
+type AvailableOption func(*ListOptions)
+
+// ListOptions are options for listing the available font families.
+type ListOptions struct {
+	// Sort is the sort order.
+	Sort string `json:"sort,omitempty"`
+	// Subset is the subset to filter by.
+	Subset string `json:"subset,omitempty"`
+	// Families are the families to filter by.
+	Families []string `json:"families,omitempty"`
+	// Categories are the categories to filter by.
+	Categories []string `json:"categories,omitempty"`
+	// Capabilities are the requested capabilities.
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// newAvailableOptions builds available options.
+func newAvailableOptions(opts ...AvailableOption) *ListOptions {
+	o := new(ListOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Sort orders.
+const (
+	SortAlpha      = "alpha"
+	SortDate       = "date"
+	SortPopularity = "popularity"
+	SortStyle      = "style"
+	SortTrending   = "trending"
+)
+
+// WithSort is an available option to set the sort order of the returned
+// webfonts ("alpha", "date", "popularity", "style", or "trending").
+func WithSort(sort string) AvailableOption {
+	return func(o *ListOptions) {
+		o.Sort = sort
+	}
+}
+
+// WithCategory is an available option to filter the returned webfonts by
+// category ("serif", "sans-serif", "display", "handwriting", or "monospace").
+func WithCategory(categories ...string) AvailableOption {
+	return func(o *ListOptions) {
+		o.Categories = append(o.Categories, categories...)
+	}
+}
+
+// WithSubsetFilter is an available option to filter the returned webfonts to
+// those supporting the subset.
+func WithSubsetFilter(subset string) AvailableOption {
+	return func(o *ListOptions) {
+		o.Subset = subset
+	}
+}
+
+// WithFamilyFilter is an available option to filter the returned webfonts to
+// the specified families.
+func WithFamilyFilter(families ...string) AvailableOption {
+	return func(o *ListOptions) {
+		o.Families = append(o.Families, families...)
+	}
+}
+
+// Capabilities.
+const (
+	CapabilityVF    = "VF"
+	CapabilityWOFF2 = "WOFF2"
+)
+
+// WithCapability is an available option to set the requested capabilities
+// (see CapabilityVF, CapabilityWOFF2). When CapabilityVF is requested,
+// variable font axes and file urls are included in the returned webfonts.
+func WithCapability(capabilities ...string) AvailableOption {
+	return func(o *ListOptions) {
+		o.Capabilities = append(o.Capabilities, capabilities...)
+	}
+}
+
 // QueryOption is a webfonts query option.
 type QueryOption func(*Query)
 
@@ -442,15 +1407,59 @@ func WithText(text string) QueryOption {
 	}
 }
 
+// WithAxes is a query option to set variable font axes values. Values are
+// either single values (400) or ranges (100..900).
+func WithAxes(axes map[string][]string) QueryOption {
+	return func(q *Query) {
+		if q.Axes == nil {
+			q.Axes = make(map[string][]string)
+		}
+		for axis, values := range axes {
+			q.Axes[axis] = append(q.Axes[axis], values...)
+		}
+	}
+}
+
+// WithAxisRange is a query option to add a variable font axis range.
+func WithAxisRange(axis string, min, max float64) QueryOption {
+	return WithAxes(map[string][]string{
+		axis: {formatAxisValue(min) + ".." + formatAxisValue(max)},
+	})
+}
+
+// contains returns true when v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
+
 // User agents.
 const (
 	UserAgentEOT   = "Mozilla/4.0 (compatible; MSIE 8.0; Windows NT 6.1; Trident/4.0)"
 	UserAgentSVG   = "Mozilla/4.0 (iPad; CPU OS 4_0_1 like Mac OS X) AppleWebKit/534.46 (KHTML, like Gecko) Version/4.1 Mobile/9A405 Safari/7534.48.3"
 	UserAgentTTF   = "Mozilla/5.0 (Unknown; Linux x86_64) AppleWebKit/538.1 (KHTML, like Gecko) Safari/538.1 Daum/4.1"
+	UserAgentOTF   = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_6_8) AppleWebKit/534.59.10 (KHTML, like Gecko) Version/5.1.9 Safari/534.59.10"
 	UserAgentWOFF2 = "Mozilla/5.0 (Windows NT 6.1; WOW64; rv:40.0) Gecko/20100101 Firefox/40.0"
 	UserAgentWOFF  = "Mozilla/5.0 (Windows NT 6.1; WOW64; rv:27.0) Gecko/20100101 Firefox/27.0"
 )
 
+// DefaultCacheTTL is the default disk cache ttl.
+var DefaultCacheTTL = 24 * time.Hour
+
+// DefaultCacheOptions returns the default disk cache options, used when no
+// disk cache options have been set (see WithCacheOptions).
+func DefaultCacheOptions() []diskcache.Option {
+	return []diskcache.Option{
+		diskcache.WithHeaderWhitelist("Date", "Set-Cookie", "Content-Type", "Location", "ETag", "Last-Modified"),
+		diskcache.WithErrorTruncator(),
+		diskcache.WithGzipCompression(),
+	}
+}
+
 // Error is a client error.
 type Error string
 
@@ -459,10 +1468,173 @@ func (err Error) Error() string {
 	return string(err)
 }
 
+// StatusError is a http status error.
+type StatusError struct {
+	// Code is the http status code.
+	Code int
+	// Body is the (truncated) response body.
+	Body []byte
+	// URL is the requested url.
+	URL string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// newStatusError creates a status error for the response, reading up to
+// MaxErrorBodySize of the response body.
+func newStatusError(urlstr string, res *http.Response) *StatusError {
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, MaxErrorBodySize))
+	return &StatusError{
+		Code: res.StatusCode,
+		Body: body,
+		URL:  urlstr,
+	}
+}
+
+// wrapAPIError wraps a google api error as a status error.
+func wrapAPIError(urlstr string, err error) error {
+	var e *googleapi.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	return &StatusError{
+		Code: e.Code,
+		Body: []byte(e.Body),
+		URL:  urlstr,
+		Err:  err,
+	}
+}
+
+// Error satisfies the error interface.
+func (err *StatusError) Error() string {
+	s := fmt.Sprintf("status %d", err.Code)
+	if text := http.StatusText(err.Code); text != "" {
+		s += " " + strings.ToLower(text)
+	}
+	if err.Err != nil {
+		return s + ": " + err.Err.Error()
+	}
+	return s
+}
+
+// Unwrap satisfies the errors.Unwrap interface.
+func (err *StatusError) Unwrap() error {
+	return err.Err
+}
+
+// Is satisfies the errors.Is interface. A status error is always
+// ErrStatusNotOK.
+func (err *StatusError) Is(target error) bool {
+	return target == ErrStatusNotOK
+}
+
+// Temporary returns true when the status code indicates a transient failure
+// (429 or 5xx).
+func (err *StatusError) Temporary() bool {
+	return err.Code == http.StatusTooManyRequests || err.Code >= http.StatusInternalServerError
+}
+
+// MaxErrorBodySize is the maximum response body size retained by a status
+// error.
+var MaxErrorBodySize int64 = 64 * 1024
+
+// MultiError wraps multiple errors.
+type MultiError []error
+
+// Error satisfies the error interface.
+func (err MultiError) Error() string {
+	var s []string
+	for _, e := range err {
+		s = append(s, e.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+// Unwrap returns the wrapped errors.
+func (err MultiError) Unwrap() []error {
+	return err
+}
+
+// Is satisfies the errors.Is interface.
+func (err MultiError) Is(target error) bool {
+	for _, e := range err {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FamilyError is a family retrieval error, wrapping the underlying error
+// with the family, format, and url being retrieved.
+type FamilyError struct {
+	// Family is the family.
+	Family string
+	// Format is the font format, if any.
+	Format Format
+	// URL is the stylesheet or font file url, if any.
+	URL string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (err *FamilyError) Error() string {
+	s := err.Family
+	if err.Format != "" {
+		s += " (" + string(err.Format) + ")"
+	}
+	if err.URL != "" {
+		s += " " + err.URL
+	}
+	return s + ": " + err.Err.Error()
+}
+
+// Unwrap satisfies the errors.Unwrap interface.
+func (err *FamilyError) Unwrap() error {
+	return err.Err
+}
+
+// Families returns the distinct families of the wrapped family errors, in
+// order.
+func (err MultiError) Families() []string {
+	var families []string
+	for _, e := range err {
+		var fe *FamilyError
+		if errors.As(e, &fe) && !contains(families, fe.Family) {
+			families = append(families, fe.Family)
+		}
+	}
+	return families
+}
+
 // Errors.
+//
+// Errors returned by the client wrap these errors with contextual data, as a
+// *FamilyError (family, format, url), *StatusError (status code, url),
+// *NotFoundError (family, suggestions), *VerifyError, or MultiError of any of
+// these. Use errors.Is to check for these errors, and errors.As to retrieve
+// the contextual data.
 const (
-	ErrServiceUninitialized Error = "service uninitialized"
-	ErrClientUninitialized  Error = "client uninitialized"
-	ErrStatusNotOK          Error = "status not ok"
-	ErrFormatNotAvailable   Error = "format not available"
+	ErrServiceUninitialized     Error = "service uninitialized"
+	ErrClientUninitialized      Error = "client uninitialized"
+	ErrStatusNotOK              Error = "status not ok"
+	ErrFormatNotAvailable       Error = "format not available"
+	ErrFamilyNotFound           Error = "family not found"
+	ErrVerifyFailed             Error = "verify failed"
+	ErrFormatMismatch           Error = "format mismatch"
+	ErrRangeNotCovered          Error = "unicode-range not covered"
+	ErrManifestVersion          Error = "unsupported manifest version"
+	ErrNotAvailableOffline      Error = "not available offline"
+	ErrInvalidFormat            Error = "invalid format"
+	ErrInvalidVariableSyntax    Error = "invalid variable syntax"
+	ErrInvalidEncoding          Error = "invalid encoding"
+	ErrInvalidQueryURL          Error = "invalid query url"
+	ErrClientClosed             Error = "client closed"
+	ErrNotRecorded              Error = "not recorded"
+	ErrInvalidWeight            Error = "invalid weight"
+	ErrInvalidVariant           Error = "invalid variant"
+	ErrInvalidStyle             Error = "invalid style"
+	ErrInvalidSrc               Error = "invalid src"
+	ErrClientOptionsUnsupported Error = "client options unsupported"
 )
@@ -0,0 +1,278 @@
+package webfonts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mirror retrieves the font files for all available font families (see
+// Available), writing each family's font files to a separate directory in
+// dir (<dir>/<family>/), and a manifest (see Manifest) describing the
+// mirrored families and font files. By default, only woff2 font files for all
+// of a family's variants and subsets are retrieved.
+//
+// Mirroring is resumable and incremental: families in an existing manifest
+// with an unchanged version and last modified date are not retrieved again.
+// The manifest is written periodically while mirroring and before returning.
+//
+// Failed families are returned as a MultiError of *FamilyError, and are
+// retried on the next run. Mirroring stops at the first failed family unless
+// continuing on error (see WithContinueOnError).
+func (cl *Client) Mirror(ctx context.Context, dir string, opts ...MirrorOption) error {
+	o := newMirrorOptions(opts...)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// load manifest
+	m, err := LoadManifest(dir)
+	switch {
+	case os.IsNotExist(err):
+		m = new(Manifest)
+	case err != nil:
+		return err
+	}
+	q := NewQuery("", o.queryOpts...)
+	prev, _ := json.Marshal(m.Query)
+	next, _ := json.Marshal(q)
+	if m.All != o.all || !bytes.Equal(prev, next) {
+		m.Families = nil
+	}
+	m.Query, m.All, m.Filter = q, o.all, newAvailableOptions(o.availableOpts...)
+	_, err = cl.update(ctx, dir, m, o, false)
+	return err
+}
+
+// Sync incrementally updates the mirror in dir (see Mirror), using the
+// options recorded in the mirror's manifest. Only families with a changed
+// version or last modified date, or with missing font files, are retrieved
+// again. Families no longer available are removed from the mirror.
+//
+// Returns the families added, updated, and removed. When a family fails, the
+// changes made are returned along with the error.
+func (cl *Client) Sync(ctx context.Context, dir string) (*SyncDiff, error) {
+	m, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	o := &mirrorOptions{
+		all: m.All,
+	}
+	if m.Query != nil {
+		o.queryOpts = append(o.queryOpts, withQuery(m.Query))
+	}
+	if m.Filter != nil {
+		filter := *m.Filter
+		o.availableOpts = append(o.availableOpts, func(lo *ListOptions) {
+			*lo = filter
+		})
+	}
+	return cl.update(ctx, dir, m, o, true)
+}
+
+// SyncDiff is the families changed by Sync.
+type SyncDiff struct {
+	// Added are the retrieved families not previously in the mirror.
+	Added []string `json:"added,omitempty"`
+	// Updated are the retrieved families previously in the mirror.
+	Updated []string `json:"updated,omitempty"`
+	// Removed are the families removed from the mirror.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Empty returns true when there are no changes.
+func (d *SyncDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Updated) == 0 && len(d.Removed) == 0
+}
+
+// update retrieves the available families, retrieving the font files for
+// families not current in the manifest, and writing the manifest to dir. When
+// remove is true, families no longer available are removed.
+func (cl *Client) update(ctx context.Context, dir string, m *Manifest, o *mirrorOptions, remove bool) (*SyncDiff, error) {
+	families, err := cl.Available(ctx, o.availableOpts...)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]ManifestFamily, len(m.Families))
+	for _, family := range m.Families {
+		entries[family.Family] = family
+	}
+	// write writes the manifest
+	last := time.Now()
+	write := func() error {
+		last = time.Now()
+		m.Generated, m.Families = last.UTC(), make([]ManifestFamily, 0, len(entries))
+		for _, family := range entries {
+			m.Families = append(m.Families, family)
+		}
+		return WriteManifest(dir, m)
+	}
+	// remove
+	diff := new(SyncDiff)
+	if remove {
+		available := make(map[string]bool, len(families))
+		for _, family := range families {
+			available[family.Name] = true
+		}
+		for _, family := range m.Families {
+			if available[family.Family] {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(dir, mirrorDir(family.Family))); err != nil {
+				return nil, err
+			}
+			delete(entries, family.Family)
+			diff.Removed = append(diff.Removed, family.Family)
+		}
+	}
+	// retrieve
+	var errs MultiError
+	for _, family := range families {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		prev, ok := entries[family.Name]
+		if ok && prev.current(family) && prev.exists(dir) {
+			continue
+		}
+		e, err := cl.fetchFamily(ctx, dir, family, o)
+		if err != nil {
+			errs = append(errs, err)
+			if !cl.continueOnError {
+				break
+			}
+			continue
+		}
+		entries[family.Name] = e
+		if ok {
+			diff.Updated = append(diff.Updated, family.Name)
+		} else {
+			diff.Added = append(diff.Added, family.Name)
+		}
+		if time.Since(last) > time.Second {
+			if err := write(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := write(); err != nil {
+		return nil, err
+	}
+	if len(errs) != 0 {
+		return diff, errs
+	}
+	return diff, nil
+}
+
+// fetchFamily retrieves the font files for the family, writing the font
+// files to the family's directory in dir.
+func (cl *Client) fetchFamily(ctx context.Context, dir string, family Family, o *mirrorOptions) (ManifestFamily, error) {
+	queryOpts := append([]QueryOption{WithVariants(family.Variants...)}, o.queryOpts...)
+	// retrieve faces
+	var fonts []Font
+	var err error
+	if o.all {
+		fonts, err = cl.All(ctx, family.Name, queryOpts...)
+	} else {
+		fonts, err = cl.Faces(ctx, family.Name, queryOpts...)
+	}
+	if err != nil {
+		var fe *FamilyError
+		if !errors.As(err, &fe) {
+			err = &FamilyError{Family: family.Name, Err: err}
+		}
+		return ManifestFamily{}, err
+	}
+	// download
+	name := mirrorDir(family.Name)
+	if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+		return ManifestFamily{}, err
+	}
+	files, err := cl.DownloadFonts(ctx, fonts, filepath.Join(dir, name))
+	if err != nil {
+		return ManifestFamily{}, err
+	}
+	for i := range files {
+		if files[i].Integrity == "" {
+			buf, err := ioutil.ReadFile(files[i].Path)
+			if err != nil {
+				return ManifestFamily{}, err
+			}
+			files[i].Integrity = Integrity(buf)
+		}
+		files[i].Path = name + "/" + filepath.Base(files[i].Path)
+	}
+	e := ManifestFamily{
+		Family:   family.Name,
+		Version:  family.Version,
+		Variants: family.Variants,
+		Files:    files,
+	}
+	if !family.LastModified.IsZero() {
+		e.LastModified = &family.LastModified
+	}
+	return e, nil
+}
+
+// mirrorDir returns the mirror directory name for the family.
+func mirrorDir(family string) string {
+	return strings.Trim(fileNameRE.ReplaceAllString(strings.ToLower(family), "-"), "-")
+}
+
+// MirrorOption is a mirror option.
+type MirrorOption func(*mirrorOptions)
+
+// mirrorOptions are mirror options.
+type mirrorOptions struct {
+	availableOpts []AvailableOption
+	queryOpts     []QueryOption
+	all           bool
+}
+
+// newMirrorOptions builds mirror options.
+func newMirrorOptions(opts ...MirrorOption) *mirrorOptions {
+	o := new(mirrorOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMirrorAvailableOptions is a mirror option to set the options used to
+// retrieve the available families, allowing the mirrored families to be
+// filtered (see WithCategory, WithSubsetFilter, WithFamilyFilter).
+func WithMirrorAvailableOptions(opts ...AvailableOption) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.availableOpts = append(o.availableOpts, opts...)
+	}
+}
+
+// WithMirrorQueryOptions is a mirror option to set additional query options
+// used to retrieve each family.
+func WithMirrorQueryOptions(opts ...QueryOption) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.queryOpts = append(o.queryOpts, opts...)
+	}
+}
+
+// WithMirrorSubsets is a mirror option to limit the retrieved subsets.
+func WithMirrorSubsets(subsets ...string) MirrorOption {
+	return func(o *mirrorOptions) {
+		o.queryOpts = append(o.queryOpts, WithSubsets(subsets...))
+	}
+}
+
+// WithMirrorAllFormats is a mirror option to retrieve all common font formats
+// for each family (see Client.All).
+func WithMirrorAllFormats() MirrorOption {
+	return func(o *mirrorOptions) {
+		o.all = true
+	}
+}
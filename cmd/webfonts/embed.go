@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kenshaw/webfonts"
+)
+
+// runEmbed bundles the families, and generates a go source file embedding the
+// bundled font files and stylesheet. Intended for use with go generate:
+//
+//	//go:generate webfonts embed -o fonts.go 'Open Sans' Roboto
+func runEmbed(ctx context.Context, f *flags, args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify at least one family")
+	}
+	switch {
+	case !token.IsIdentifier(f.pkg):
+		return fmt.Errorf("invalid package name %q (set -pkg or $GOPACKAGE)", f.pkg)
+	case f.dir == "" || filepath.IsAbs(f.dir) || strings.Contains(filepath.ToSlash(f.dir), ".."):
+		return fmt.Errorf("invalid dir %q: must be a path relative to the go source file", f.dir)
+	}
+	opts, err := f.bundleOpts()
+	if err != nil {
+		return err
+	}
+	// bundle
+	dir := filepath.Join(filepath.Dir(f.out), f.dir)
+	if err := webfonts.Bundle(ctx, args, dir, opts...); err != nil {
+		return err
+	}
+	stylesheet, err := ioutil.ReadFile(filepath.Join(dir, "fonts.css"))
+	if err != nil {
+		return err
+	}
+	// generate
+	buf := new(bytes.Buffer)
+	if err := embedTpl.Execute(buf, map[string]interface{}{
+		"args":       strings.Join(quoteArgs(os.Args[1:]), " "),
+		"pkg":        f.pkg,
+		"dir":        path.Clean(filepath.ToSlash(f.dir)),
+		"families":   strings.Join(args, ", "),
+		"stylesheet": quoteString(string(stylesheet)),
+	}); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(f.out, src, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "wrote: %s %s\n", f.out, dir)
+	return nil
+}
+
+// quoteString quotes s as a go string literal, using a raw string literal
+// when possible.
+func quoteString(s string) string {
+	if strconv.CanBackquote(strings.ReplaceAll(s, "\n", "")) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// quoteArgs quotes the command line args containing spaces.
+func quoteArgs(args []string) []string {
+	v := make([]string, len(args))
+	for i, arg := range args {
+		v[i] = arg
+		if strings.ContainsAny(arg, " \t'\"") {
+			v[i] = strconv.Quote(arg)
+		}
+	}
+	return v
+}
+
+// embedTpl is the embed go source template.
+var embedTpl = template.Must(template.New("embed").Parse(`// Code generated by webfonts {{ .args }}; DO NOT EDIT.
+
+package {{ .pkg }}
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Stylesheet is the stylesheet for the embedded fonts ({{ .families }}).
+// Font files are referred to with urls relative to the stylesheet.
+const Stylesheet = {{ .stylesheet }}
+
+// files are the embedded font files.
+//
+//go:embed {{ .dir }}
+var files embed.FS
+
+// FS is a file system containing the embedded font files and stylesheet
+// (fonts.css).
+var FS fs.FS = func() fs.FS {
+	f, err := fs.Sub(files, "{{ .dir }}")
+	if err != nil {
+		panic(err)
+	}
+	return f
+}()
+`))
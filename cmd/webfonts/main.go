@@ -0,0 +1,642 @@
+// Command webfonts lists, retrieves, bundles, serves, inspects, mirrors,
+// syncs, and localizes webfonts.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kenshaw/httplog"
+	"github.com/kenshaw/webfonts"
+	"github.com/kenshaw/webfonts/convert"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// command is a sub command.
+type command struct {
+	name  string
+	usage string
+	desc  string
+	run   func(context.Context, *flags, []string) error
+}
+
+// commands are the sub commands.
+var commands = []command{
+	{"list", "[flags]", "list available families", runList},
+	{"get", "[flags] <family>...", "download font files for families", runGet},
+	{"bundle", "[flags] <family>...", "write a self-hosted fonts directory", runBundle},
+	{"serve", "[flags] <family>...", "serve font stylesheets and font files", runServe},
+	{"inspect", "[flags] <file>...", "inspect font files", runInspect},
+	{"embed", "[flags] <family>...", "generate a go source file embedding font files", runEmbed},
+	{"mirror", "[flags] [family]...", "mirror the font files for all available families", runMirror},
+	{"sync", "[flags]", "update a mirror with changed families", runSync},
+	{"localize", "[flags] <file>", "self-host the google fonts referenced by a html file", runLocalize},
+	{"doctor", "[flags]", "run diagnostic checks", runDoctor},
+}
+
+// run runs the sub command in args.
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage()
+		return flag.ErrHelp
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		f := newFlags(c)
+		if err := f.fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		// read secrets from the environment after parsing, so that they are
+		// not printed in the flag defaults
+		if f.key == "" {
+			f.key = envKey()
+		}
+		if f.adminToken == "" {
+			f.adminToken = os.Getenv("WEBFONTS_ADMIN_TOKEN")
+		}
+		return c.run(ctx, f, f.fs.Args())
+	}
+	usage()
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// usage writes the usage to stderr.
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: webfonts <command> [flags] [args]\n\ncommands:\n")
+	w := tabwriter.NewWriter(os.Stderr, 0, 8, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", c.name, c.desc)
+	}
+	w.Flush()
+	fmt.Fprintf(os.Stderr, "\nrun 'webfonts <command> -h' for command flags\n")
+}
+
+// flags are the command line flags.
+type flags struct {
+	fs *flag.FlagSet
+	// client
+	verbose      bool
+	key          string
+	adc          bool
+	cacheDir     string
+	fontCacheDir string
+	provider     string
+	local        string
+	userAgent    string
+	timeout      time.Duration
+	catalogTTL   time.Duration
+	// query
+	formats  string
+	subsets  string
+	variants string
+	display  string
+	text     string
+	effects  string
+	// output
+	out  string
+	json bool
+	// list
+	sort     string
+	category string
+	italic   bool
+	variable bool
+	// bundle
+	convert   string
+	inline    int64
+	naming    string
+	modern    bool
+	variables string
+	verify    bool
+	integrity bool
+	cont      bool
+	progress  bool
+	// serve
+	addr       string
+	prefix     string
+	admin      bool
+	adminToken string
+	lazy       bool
+	// embed
+	dir string
+	pkg string
+}
+
+// newFlags creates the flags for the command.
+func newFlags(c command) *flags {
+	f := &flags{
+		fs: flag.NewFlagSet(c.name, flag.ContinueOnError),
+	}
+	f.fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: webfonts %s %s\n\n%s\n\nflags:\n", c.name, c.usage, c.desc)
+		f.fs.PrintDefaults()
+	}
+	f.fs.BoolVar(&f.verbose, "v", false, "verbose")
+	f.fs.StringVar(&f.key, "key", "", "google webfonts api key (default: $WEBFONTS_KEY or $GOOGLE_FONTS_API_KEY)")
+	f.fs.BoolVar(&f.adc, "default-credentials", false, "use application default credentials when no api key is set")
+	f.fs.StringVar(&f.cacheDir, "cache-dir", "webfonts", "app cache dir (empty disables caching)")
+	f.fs.StringVar(&f.fontCacheDir, "font-cache-dir", "", "font file cache dir (font files are cached without expiration)")
+	f.fs.StringVar(&f.provider, "provider", "google", "font provider (google, bunny)")
+	f.fs.StringVar(&f.local, "local", "", "local mirror or bundle dir to use instead of the network")
+	f.fs.StringVar(&f.userAgent, "user-agent", "", "user agent for stylesheet requests (default: current chrome user agent)")
+	f.fs.DurationVar(&f.timeout, "timeout", webfonts.DefaultRequestTimeout, "timeout for each request (0 disables)")
+	f.fs.DurationVar(&f.catalogTTL, "catalog-ttl", 24*time.Hour, "available families cache ttl (0 disables)")
+	switch c.name {
+	case "list":
+		f.fs.StringVar(&f.sort, "sort", "", "sort order (alpha, date, popularity, style, trending)")
+		f.fs.StringVar(&f.category, "category", "", "comma separated categories to filter by")
+		f.fs.StringVar(&f.subsets, "subset", "", "subset to filter by")
+		f.fs.BoolVar(&f.italic, "italic", false, "only list families with italic variants")
+		f.fs.BoolVar(&f.variable, "variable", false, "only list variable families")
+		f.fs.BoolVar(&f.json, "json", false, "write json")
+	case "get", "bundle", "serve", "embed":
+		f.fs.StringVar(&f.formats, "formats", "woff2", "comma separated font formats (woff2, woff, ttf, otf, svg, eot, all)")
+		f.fs.StringVar(&f.subsets, "subsets", "", "comma separated subsets")
+		f.fs.StringVar(&f.variants, "variants", "", "comma separated variants (ie, regular,700,700italic)")
+		f.fs.StringVar(&f.display, "display", "", "font-display value")
+		f.fs.StringVar(&f.text, "text", "", "text to limit retrieved glyphs to")
+	case "inspect", "doctor":
+		f.fs.BoolVar(&f.json, "json", false, "write json")
+	case "mirror":
+		f.fs.StringVar(&f.formats, "formats", "woff2", "font formats (woff2, all)")
+		f.fs.StringVar(&f.subsets, "subsets", "", "comma separated subsets")
+		f.fs.StringVar(&f.category, "category", "", "comma separated categories to filter by")
+	}
+	switch c.name {
+	case "get", "bundle", "mirror", "sync", "localize":
+		f.fs.StringVar(&f.out, "o", "fonts", "output dir")
+	case "embed":
+		f.fs.StringVar(&f.out, "o", "webfonts.go", "output go source file")
+		f.fs.StringVar(&f.dir, "dir", "fonts", "font files dir, relative to the go source file")
+		f.fs.StringVar(&f.pkg, "pkg", os.Getenv("GOPACKAGE"), "go package name (default: $GOPACKAGE)")
+	}
+	switch c.name {
+	case "get", "bundle", "embed", "mirror", "sync", "localize":
+		f.fs.BoolVar(&f.verify, "verify", false, "verify downloaded font files")
+		f.fs.BoolVar(&f.integrity, "integrity", false, "write subresource integrity manifest")
+		f.fs.BoolVar(&f.cont, "continue", false, "continue when a family or font file fails")
+		f.fs.BoolVar(&f.progress, "progress", false, "write progress to stderr")
+	}
+	switch c.name {
+	case "bundle", "serve", "embed":
+		f.fs.StringVar(&f.effects, "effects", "", "comma separated font effects to include in stylesheets (ie, shadow-multiple)")
+	}
+	switch c.name {
+	case "bundle", "embed":
+		f.fs.StringVar(&f.convert, "convert", "", "comma separated formats to convert woff2 font files to (ttf, otf, woff)")
+		f.fs.Int64Var(&f.inline, "inline", -1, "inline font files smaller than or equal to size in the stylesheet (0 inlines all, -1 disables)")
+		f.fs.StringVar(&f.naming, "naming", "hash", "font file naming (hash, verbose)")
+		f.fs.BoolVar(&f.modern, "modern", false, "write a modern woff2 only stylesheet")
+		f.fs.StringVar(&f.variables, "variables", "", "comma separated font stack variable syntaxes to write (css, scss, less)")
+	case "serve":
+		f.fs.StringVar(&f.addr, "l", ":9090", "listen address")
+		f.fs.StringVar(&f.prefix, "prefix", "/", "url path prefix")
+		f.fs.BoolVar(&f.admin, "admin", false, "enable the admin endpoint for adding and removing families ("+adminPath+")")
+		f.fs.StringVar(&f.adminToken, "admin-token", "", "admin endpoint bearer token (default: $WEBFONTS_ADMIN_TOKEN, or a generated token)")
+		f.fs.BoolVar(&f.lazy, "lazy", false, "retrieve font files on first request")
+	}
+	return f
+}
+
+// envKey returns the google webfonts api key from the environment.
+func envKey() string {
+	if key := os.Getenv("WEBFONTS_KEY"); key != "" {
+		return key
+	}
+	return os.Getenv("GOOGLE_FONTS_API_KEY")
+}
+
+// clientOpts returns the client options for the flags.
+func (f *flags) clientOpts() ([]webfonts.ClientOption, error) {
+	var opts []webfonts.ClientOption
+	if f.verbose {
+		opts = append(opts, webfonts.WithLogf(func(s string, v ...interface{}) {
+			fmt.Fprintf(os.Stderr, s, v...)
+		}, httplog.WithReqResBody(false, false)))
+	}
+	if f.key != "" {
+		opts = append(opts, webfonts.WithKey(f.key))
+	}
+	if f.adc {
+		opts = append(opts, webfonts.WithDefaultCredentials())
+	}
+	if f.cacheDir != "" {
+		opts = append(opts, webfonts.WithAppCacheDir(f.cacheDir))
+	}
+	if f.fontCacheDir != "" {
+		opts = append(opts, webfonts.WithFontCacheDir(f.fontCacheDir))
+	}
+	if f.local != "" {
+		opts = append(opts, webfonts.WithLocalSource(f.local))
+	}
+	if f.userAgent != "" {
+		opts = append(opts, webfonts.WithDefaultUserAgent(f.userAgent))
+	}
+	if f.catalogTTL > 0 {
+		opts = append(opts, webfonts.WithCatalogTTL(f.catalogTTL))
+	}
+	if f.timeout != webfonts.DefaultRequestTimeout {
+		opts = append(opts, webfonts.WithRequestTimeout(f.timeout))
+	}
+	switch f.provider {
+	case "google":
+	case "bunny":
+		opts = append(opts, webfonts.WithProvider(webfonts.Bunny))
+	default:
+		return nil, fmt.Errorf("unknown provider %q", f.provider)
+	}
+	switch formats := split(f.formats); {
+	case len(formats) == 0, contains(formats, "all"), len(formats) == 1 && formats[0] == "woff2":
+	default:
+		var v []webfonts.Format
+		for _, s := range formats {
+			format, err := webfonts.ParseFormat(s)
+			if err != nil {
+				return nil, err
+			}
+			v = append(v, format)
+		}
+		opts = append(opts, webfonts.WithFormats(v...))
+	}
+	if f.verify {
+		opts = append(opts, webfonts.WithVerify(true))
+	}
+	if f.integrity {
+		opts = append(opts, webfonts.WithIntegrity(true))
+	}
+	if f.cont {
+		opts = append(opts, webfonts.WithContinueOnError(true))
+	}
+	if f.progress {
+		opts = append(opts, webfonts.WithProgress(func(ev webfonts.ProgressEvent) {
+			if ev.Done {
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s %s (%d bytes)\n", ev.Files, ev.Total, ev.Family, ev.Format, ev.Bytes)
+			}
+		}))
+	}
+	return opts, nil
+}
+
+// queryOpts returns the query options for the flags.
+func (f *flags) queryOpts() []webfonts.QueryOption {
+	var opts []webfonts.QueryOption
+	if v := split(f.subsets); len(v) != 0 {
+		opts = append(opts, webfonts.WithSubsets(v...))
+	}
+	if v := split(f.variants); len(v) != 0 {
+		opts = append(opts, webfonts.WithVariants(v...))
+	}
+	if f.display != "" {
+		opts = append(opts, webfonts.WithDisplay(f.display))
+	}
+	if f.text != "" {
+		opts = append(opts, webfonts.WithText(f.text))
+	}
+	if v := split(f.effects); len(v) != 0 {
+		opts = append(opts, webfonts.WithEffects(v...))
+	}
+	return opts
+}
+
+// faces retrieves the font faces for the family in the formats (see
+// clientOpts).
+func (f *flags) faces(ctx context.Context, cl *webfonts.Client, family string) ([]webfonts.Font, error) {
+	formats := split(f.formats)
+	if len(formats) == 1 && formats[0] == "woff2" {
+		return cl.Faces(ctx, family, f.queryOpts()...)
+	}
+	return cl.All(ctx, family, f.queryOpts()...)
+}
+
+// runList lists the available families.
+func runList(ctx context.Context, f *flags, args []string) error {
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	var opts []webfonts.AvailableOption
+	if f.sort != "" {
+		opts = append(opts, webfonts.WithSort(f.sort))
+	}
+	if v := split(f.category); len(v) != 0 {
+		opts = append(opts, webfonts.WithCategory(v...))
+	}
+	if f.subsets != "" {
+		opts = append(opts, webfonts.WithSubsetFilter(f.subsets))
+	}
+	if f.variable {
+		opts = append(opts, webfonts.WithCapability(webfonts.CapabilityVF))
+	}
+	if len(args) != 0 {
+		opts = append(opts, webfonts.WithFamilyFilter(args...))
+	}
+	families, err := webfonts.NewClient(clientOpts...).Available(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	families = webfonts.FilterFamilies(families, webfonts.FilterOptions{
+		SupportsItalic: f.italic,
+		VariableOnly:   f.variable,
+	})
+	if f.json {
+		return writeJSON(families)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, family := range families {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", family.Name, family.Category, strings.Join(family.Variants, ","), strings.Join(family.Subsets, ","))
+	}
+	return w.Flush()
+}
+
+// runGet downloads the font files for the families.
+func runGet(ctx context.Context, f *flags, args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify at least one family")
+	}
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	cl := webfonts.NewClient(clientOpts...)
+	var errs webfonts.MultiError
+	var fonts []webfonts.Font
+	for _, family := range args {
+		v, err := f.faces(ctx, cl, family)
+		switch {
+		case err != nil && !f.cont:
+			return fmt.Errorf("unable to retrieve %s: %w", family, err)
+		case err != nil:
+			errs = append(errs, err)
+		}
+		fonts = append(fonts, v...)
+	}
+	files, err := cl.DownloadFonts(ctx, fonts, f.out)
+	switch {
+	case err != nil && !f.cont:
+		return err
+	case err != nil:
+		errs = append(errs, err)
+	}
+	for _, file := range files {
+		fmt.Fprintf(os.Stdout, "%s (%d bytes)\n", file.Path, file.Size)
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// runBundle writes a self-hosted fonts directory for the families.
+func runBundle(ctx context.Context, f *flags, args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify at least one family")
+	}
+	opts, err := f.bundleOpts()
+	if err != nil {
+		return err
+	}
+	if err := webfonts.Bundle(ctx, args, f.out, opts...); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "wrote: %s\n", f.out)
+	return nil
+}
+
+// bundleOpts returns the bundle options for the flags.
+func (f *flags) bundleOpts() ([]webfonts.BundleOption, error) {
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return nil, err
+	}
+	opts := []webfonts.BundleOption{
+		webfonts.WithBundleClientOptions(clientOpts...),
+		webfonts.WithBundleQueryOptions(f.queryOpts()...),
+	}
+	if formats := split(f.formats); len(formats) != 1 || formats[0] != "woff2" {
+		opts = append(opts, webfonts.WithBundleAllFormats())
+	}
+	var formats []webfonts.Format
+	for _, s := range split(f.convert) {
+		format, err := webfonts.ParseFormat(s)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, format)
+	}
+	if len(formats) != 0 {
+		opts = append(opts, webfonts.WithBundleConvert(formats...))
+	}
+	if f.inline >= 0 {
+		opts = append(opts, webfonts.WithBundleInline(f.inline))
+	}
+	var syntaxes []webfonts.VariableSyntax
+	for _, s := range split(f.variables) {
+		syntaxes = append(syntaxes, webfonts.VariableSyntax(s))
+	}
+	if len(syntaxes) != 0 {
+		opts = append(opts, webfonts.WithBundleVariables(syntaxes...))
+	}
+	if f.modern {
+		opts = append(opts, webfonts.WithBundleRouteOptions(webfonts.WithModernStylesheet(true)))
+	}
+	switch f.naming {
+	case "hash":
+	case "verbose":
+		opts = append(opts, webfonts.WithBundleRouteOptions(webfonts.WithRouteNaming(webfonts.VerboseName)))
+	default:
+		return nil, fmt.Errorf("invalid naming %q", f.naming)
+	}
+	return opts, nil
+}
+
+// runMirror mirrors the font files for all available families, or the
+// families in args.
+func runMirror(ctx context.Context, f *flags, args []string) error {
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	var availableOpts []webfonts.AvailableOption
+	if v := split(f.category); len(v) != 0 {
+		availableOpts = append(availableOpts, webfonts.WithCategory(v...))
+	}
+	if len(args) != 0 {
+		availableOpts = append(availableOpts, webfonts.WithFamilyFilter(args...))
+	}
+	opts := []webfonts.MirrorOption{
+		webfonts.WithMirrorAvailableOptions(availableOpts...),
+	}
+	switch f.formats {
+	case "woff2":
+	case "all":
+		opts = append(opts, webfonts.WithMirrorAllFormats())
+	default:
+		return fmt.Errorf("invalid formats %q", f.formats)
+	}
+	if v := split(f.subsets); len(v) != 0 {
+		opts = append(opts, webfonts.WithMirrorSubsets(v...))
+	}
+	if err := webfonts.NewClient(clientOpts...).Mirror(ctx, f.out, opts...); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "wrote: %s\n", f.out)
+	return nil
+}
+
+// runSync updates the mirror with changed families, writing the added,
+// updated, and removed families.
+func runSync(ctx context.Context, f *flags, args []string) error {
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	diff, err := webfonts.NewClient(clientOpts...).Sync(ctx, f.out)
+	if diff != nil {
+		for _, family := range diff.Added {
+			fmt.Fprintf(os.Stdout, "added: %s\n", family)
+		}
+		for _, family := range diff.Updated {
+			fmt.Fprintf(os.Stdout, "updated: %s\n", family)
+		}
+		for _, family := range diff.Removed {
+			fmt.Fprintf(os.Stdout, "removed: %s\n", family)
+		}
+	}
+	return err
+}
+
+// runLocalize bundles the google fonts referenced by the html file, writing
+// the html file with rewritten references to stdout.
+func runLocalize(ctx context.Context, f *flags, args []string) error {
+	if len(args) != 1 {
+		return errors.New("must specify exactly one file")
+	}
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	fh, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	buf, err := webfonts.LocalizeHTML(ctx, fh, f.out, webfonts.WithBundleClientOptions(clientOpts...))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(buf)
+	return err
+}
+
+// runDoctor runs diagnostic checks for the client's cache directory, user
+// agent resolution, endpoint reachability, clock skew, and api key validity.
+func runDoctor(ctx context.Context, f *flags, args []string) error {
+	if len(args) != 0 {
+		return errors.New("doctor does not accept arguments")
+	}
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	r := webfonts.Doctor(ctx, clientOpts...)
+	if f.json {
+		if err := writeJSON(r); err != nil {
+			return err
+		}
+	} else if _, err := os.Stdout.WriteString(r.String()); err != nil {
+		return err
+	}
+	if !r.OK() {
+		return errors.New("diagnostic checks failed")
+	}
+	return nil
+}
+
+// runInspect inspects font files.
+func runInspect(ctx context.Context, f *flags, args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify at least one file")
+	}
+	var infos []*convert.FontInfo
+	for _, name := range args {
+		fh, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		info, err := convert.Inspect(fh)
+		fh.Close()
+		if err != nil {
+			return fmt.Errorf("unable to inspect %s: %w", name, err)
+		}
+		infos = append(infos, info)
+	}
+	if f.json {
+		return writeJSON(infos)
+	}
+	for i, info := range infos {
+		if i != 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "file:\t%s\n", args[i])
+		fmt.Fprintf(w, "format:\t%s\n", info.Format)
+		fmt.Fprintf(w, "family:\t%s\n", info.Family)
+		fmt.Fprintf(w, "subfamily:\t%s\n", info.Subfamily)
+		fmt.Fprintf(w, "full name:\t%s\n", info.FullName)
+		fmt.Fprintf(w, "postscript name:\t%s\n", info.PostScriptName)
+		fmt.Fprintf(w, "version:\t%s\n", info.Version)
+		fmt.Fprintf(w, "glyphs:\t%d\n", info.Glyphs)
+		fmt.Fprintf(w, "runes:\t%d\n", len(info.Runes))
+		for _, axis := range info.Axes {
+			fmt.Fprintf(w, "axis:\t%s %g..%g (default: %g) %s\n", axis.Tag, axis.Min, axis.Max, axis.Default, axis.Name)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes v as json to stdout.
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// split splits a comma separated list.
+func split(s string) []string {
+	var v []string
+	for _, z := range strings.Split(s, ",") {
+		if z = strings.TrimSpace(z); z != "" {
+			v = append(v, z)
+		}
+	}
+	return v
+}
+
+// contains returns true when v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
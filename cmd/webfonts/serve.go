@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kenshaw/webfonts"
+)
+
+// runServe serves the font stylesheets and font files for the families.
+func runServe(ctx context.Context, f *flags, args []string) error {
+	if len(args) == 0 && !f.admin {
+		return errors.New("must specify at least one family")
+	}
+	clientOpts, err := f.clientOpts()
+	if err != nil {
+		return err
+	}
+	opts := []webfonts.HandlerOption{
+		webfonts.WithHandlerClientOptions(clientOpts...),
+		webfonts.WithHandlerQueryOptions(f.queryOpts()...),
+		webfonts.WithHandlerPrefix(f.prefix),
+	}
+	if formats := split(f.formats); len(formats) != 1 || formats[0] != "woff2" {
+		opts = append(opts, webfonts.WithHandlerAllFormats())
+	}
+	if f.lazy {
+		opts = append(opts, webfonts.WithHandlerLazy())
+	}
+	h, err := webfonts.NewHandler(ctx, args, opts...)
+	if err != nil {
+		return err
+	}
+	for _, family := range sortedFamilies(h) {
+		fmt.Fprintf(os.Stdout, "serving: %s %s\n", family.Family, family.Path)
+	}
+	var handler http.Handler = h
+	if f.admin {
+		token := f.adminToken
+		if token == "" {
+			buf := make([]byte, 16)
+			if _, err := rand.Read(buf); err != nil {
+				return err
+			}
+			token = hex.EncodeToString(buf)
+			fmt.Fprintf(os.Stdout, "admin token: %s\n", token)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/", h)
+		mux.Handle(adminPath, &admin{h: h, token: token})
+		handler = mux
+		fmt.Fprintf(os.Stdout, "admin: %s\n", adminPath)
+	}
+	// listen and serve
+	fmt.Fprintf(os.Stdout, "listening: %s\n", f.addr)
+	l, err := (&net.ListenConfig{}).Listen(ctx, "tcp", f.addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return http.Serve(l, handler)
+}
+
+// adminPath is the url path of the admin endpoint.
+const adminPath = "/_admin/families"
+
+// admin is the admin endpoint, allowing families to be added to and removed
+// from a handler at runtime.
+//
+// GET lists the served families. POST adds and removes families, using a json
+// request body of the form {"add": ["Family"], "remove": ["Family"]}. Both
+// respond with the served families. Requests must include the admin token as
+// a bearer token in the Authorization header. Removing a family that is not
+// served responds with 404.
+type admin struct {
+	h     *webfonts.Handler
+	token string
+}
+
+// adminRequest is an admin request.
+type adminRequest struct {
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// servedFamily is a served family.
+type servedFamily struct {
+	Family string `json:"family"`
+	Path   string `json:"path"`
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (a *admin) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	// authenticate
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		res.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var r adminRequest
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			http.Error(res, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		// look up the served families to remove
+		remove := make([]string, len(r.Remove))
+		for i, name := range r.Remove {
+			family, ok := servedFamilyName(a.h, name)
+			if !ok {
+				http.Error(res, fmt.Sprintf("family %q not served", name), http.StatusNotFound)
+				return
+			}
+			remove[i] = family
+		}
+		a.h.Remove(remove...)
+		if len(r.Add) != 0 {
+			if err := a.h.Add(req.Context(), r.Add...); err != nil {
+				http.Error(res, fmt.Sprintf("unable to add families: %v", err), http.StatusBadGateway)
+				return
+			}
+		}
+		fmt.Fprintf(os.Stdout, "admin: added %q, removed %q\n", r.Add, r.Remove)
+	default:
+		res.Header().Set("Allow", "GET, POST")
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(res)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(sortedFamilies(a.h))
+}
+
+// servedFamilyName returns the name of the family served by the handler
+// matching name, ignoring case and whitespace.
+func servedFamilyName(h *webfonts.Handler, name string) (string, bool) {
+	name = normalizeFamilyName(name)
+	for family := range h.Families() {
+		if normalizeFamilyName(family) == name {
+			return family, true
+		}
+	}
+	return "", false
+}
+
+// normalizeFamilyName normalizes the family name, lower casing it and
+// removing all whitespace.
+func normalizeFamilyName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "")
+}
+
+// sortedFamilies returns the families served by the handler, sorted by name.
+func sortedFamilies(h *webfonts.Handler) []servedFamily {
+	families := make([]servedFamily, 0)
+	for family, urlpath := range h.Families() {
+		families = append(families, servedFamily{
+			Family: family,
+			Path:   urlpath,
+		})
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Family < families[j].Family
+	})
+	return families
+}
@@ -0,0 +1,91 @@
+// Package convert provides font file format conversion between woff2, woff,
+// and sfnt (ttf/otf) font files, font file subsetting, and font file
+// inspection.
+package convert
+
+import (
+	"bytes"
+)
+
+// Format returns the font file format for the font data ("woff2", "woff",
+// "ttf", "otf", "eot", "svg"), or an empty string when the format is not
+// recognized.
+func Format(buf []byte) string {
+	switch {
+	case bytes.HasPrefix(buf, []byte("wOF2")):
+		return "woff2"
+	case bytes.HasPrefix(buf, []byte("wOFF")):
+		return "woff"
+	case bytes.HasPrefix(buf, []byte{0, 1, 0, 0}), bytes.HasPrefix(buf, []byte("true")):
+		return "ttf"
+	case bytes.HasPrefix(buf, []byte("OTTO")):
+		return "otf"
+	case len(buf) > 36 && buf[34] == 'L' && buf[35] == 'P':
+		return "eot"
+	case bytes.Contains(buf[:min(len(buf), 1024)], []byte("<svg")):
+		return "svg"
+	}
+	return ""
+}
+
+// Convert converts the woff2, woff, ttf, or otf font data to the specified
+// format ("ttf", "otf", "woff"). Converting to "ttf" or "otf" returns the
+// decompressed sfnt font data, which must match the font's flavor.
+func Convert(buf []byte, format string) ([]byte, error) {
+	from := Format(buf)
+	if from == format {
+		return buf, nil
+	}
+	// decompress to sfnt
+	var err error
+	switch from {
+	case "woff2":
+		if buf, err = WOFF2ToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "woff":
+		if buf, err = WOFFToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "ttf", "otf":
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	// convert
+	switch format {
+	case "ttf", "otf":
+		if Format(buf) != format {
+			return nil, ErrFlavorMismatch
+		}
+		return buf, nil
+	case "woff":
+		return SFNTToWOFF(buf)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// min returns the minimum of a, b.
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Error is a conversion error.
+type Error string
+
+// Error satisfies the error interface.
+func (err Error) Error() string {
+	return string(err)
+}
+
+// Errors.
+const (
+	ErrUnsupportedFormat     Error = "unsupported format"
+	ErrFlavorMismatch        Error = "flavor mismatch"
+	ErrInvalidFont           Error = "invalid font"
+	ErrCollectionUnsupported Error = "font collections are not supported"
+	ErrUnknownSubset         Error = "unknown subset"
+	ErrInvalidUnicodeRange   Error = "invalid unicode range"
+)
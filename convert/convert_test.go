@@ -0,0 +1,192 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestWOFF2ToSFNT(t *testing.T) {
+	buf, err := os.ReadFile("testdata/opensans-lightitalic.woff2")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := Format(buf); s != "woff2" {
+		t.Fatalf("expected woff2, got: %q", s)
+	}
+	out, err := WOFF2ToSFNT(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := Format(out); s != "ttf" {
+		t.Fatalf("expected ttf, got: %q", s)
+	}
+	checkSFNT(t, out)
+	// expect glyf and loca reconstructed
+	f, err := sfnt.Parse(out)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := f.NumGlyphs(); n < 100 {
+		t.Fatalf("expected at least 100 glyphs, got: %d", n)
+	}
+	var b sfnt.Buffer
+	for _, r := range "Aag&" {
+		i, err := f.GlyphIndex(&b, r)
+		switch {
+		case err != nil:
+			t.Fatalf("expected no error, got: %v", err)
+		case i == 0:
+			t.Fatalf("expected glyph for %q", r)
+		}
+		segs, err := f.LoadGlyph(&b, i, 64<<6, nil)
+		switch {
+		case err != nil:
+			t.Fatalf("expected no error loading %q, got: %v", r, err)
+		case len(segs) == 0:
+			t.Errorf("expected segments for %q", r)
+		}
+	}
+}
+
+func TestWOFFRoundTrip(t *testing.T) {
+	flavor, tables, err := readSFNT(goregular.TTF)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := writeSFNT(flavor, tables)
+	woff, err := Convert(goregular.TTF, "woff")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := Format(woff); s != "woff" {
+		t.Fatalf("expected woff, got: %q", s)
+	}
+	if len(woff) >= len(goregular.TTF) {
+		t.Errorf("expected woff to be smaller than %d, got: %d", len(goregular.TTF), len(woff))
+	}
+	out, err := Convert(woff, "ttf")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkSFNT(t, out)
+	if !bytes.Equal(out, exp) {
+		t.Errorf("expected round tripped sfnt to equal original")
+	}
+	if _, err := sfnt.Parse(out); err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+}
+
+func TestConvertFlavorMismatch(t *testing.T) {
+	if _, err := Convert(goregular.TTF, "otf"); !errors.Is(err, ErrFlavorMismatch) {
+		t.Errorf("expected %v, got: %v", ErrFlavorMismatch, err)
+	}
+	if _, err := Convert([]byte("not a font"), "ttf"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected %v, got: %v", ErrUnsupportedFormat, err)
+	}
+}
+
+func TestWOFF2Truncated(t *testing.T) {
+	buf, err := os.ReadFile("testdata/opensans-lightitalic.woff2")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, n := range []int{0, 4, 47, 48, 64, 128, len(buf) / 2, len(buf) * 3 / 4} {
+		if _, err := WOFF2ToSFNT(buf[:n]); err == nil {
+			t.Errorf("expected error for %d bytes", n)
+		}
+	}
+}
+
+func TestWOFF2DecompressLimit(t *testing.T) {
+	// compress 1 MiB of zeros, declared as a single 4 byte head table
+	var compressed bytes.Buffer
+	w := brotli.NewWriter(&compressed)
+	if _, err := w.Write(make([]byte, 1<<20)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf := make([]byte, 48)
+	copy(buf, "wOF2")
+	binary.BigEndian.PutUint32(buf[4:], 0x00010000)
+	binary.BigEndian.PutUint16(buf[12:], 1)
+	binary.BigEndian.PutUint32(buf[20:], uint32(compressed.Len()))
+	buf = append(buf, 1, 4) // head, origLength 4
+	buf = append(buf, compressed.Bytes()...)
+	binary.BigEndian.PutUint32(buf[8:], uint32(len(buf)))
+	if _, err := WOFF2ToSFNT(buf); !errors.Is(err, ErrInvalidFont) {
+		t.Errorf("expected %v, got: %v", ErrInvalidFont, err)
+	}
+}
+
+func TestReconstructGlyfStreamSizes(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizes [7]uint32
+	}{
+		{"huge", [7]uint32{0xffffffff}},
+		{"all huge", [7]uint32{0xffffffff, 0xffffffff, 0xffffffff, 0xffffffff, 0xffffffff, 0xffffffff, 0xffffffff}},
+		{"past end", [7]uint32{1, 1, 1, 1, 1, 1, 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := make([]byte, 8, 36)
+			binary.BigEndian.PutUint16(data[4:], 1) // numGlyphs
+			for _, size := range test.sizes {
+				data = binary.BigEndian.AppendUint32(data, size)
+			}
+			if _, _, _, err := reconstructGlyf(data); !errors.Is(err, ErrInvalidFont) {
+				t.Errorf("expected %v, got: %v", ErrInvalidFont, err)
+			}
+		})
+	}
+}
+
+func TestReaderPastEnd(t *testing.T) {
+	r := &reader{buf: []byte{1, 2}}
+	if b := r.bytes(0xffffffff); len(b) > 4 {
+		t.Errorf("expected at most 4 bytes, got: %d", len(b))
+	}
+	if r.err != ErrInvalidFont {
+		t.Errorf("expected %v, got: %v", ErrInvalidFont, r.err)
+	}
+	if v := r.u32(); v != 0 {
+		t.Errorf("expected 0, got: %d", v)
+	}
+}
+
+// checkSFNT checks the sfnt table checksums and head checksum adjustment.
+func checkSFNT(t *testing.T, buf []byte) {
+	t.Helper()
+	_, tables, err := readSFNT(buf)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var head []byte
+	for _, tbl := range tables {
+		data := tbl.data
+		if tbl.tag == "head" {
+			head = data
+			data = append([]byte(nil), data...)
+			binary.BigEndian.PutUint32(data[8:], 0)
+		}
+		if sum := checksum(data); sum != tbl.checksum {
+			t.Errorf("table %q: expected checksum %08x, got: %08x", tbl.tag, tbl.checksum, sum)
+		}
+	}
+	if head == nil {
+		t.Fatalf("expected head table")
+	}
+	if sum := checksum(buf); sum != 0xb1b0afba {
+		t.Errorf("expected font checksum b1b0afba, got: %08x", sum)
+	}
+}
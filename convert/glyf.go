@@ -0,0 +1,310 @@
+package convert
+
+import (
+	"encoding/binary"
+)
+
+// reconstructGlyf reconstructs the glyf and loca tables from a transformed
+// woff2 glyf table, returning the glyf and loca tables, and the xMin of each
+// glyph.
+func reconstructGlyf(data []byte) ([]byte, []byte, []int16, error) {
+	r := &reader{buf: data}
+	r.skip(2) // reserved
+	optionFlags := r.u16()
+	numGlyphs := int(r.u16())
+	indexFormat := r.u16()
+	var sizes [7]int
+	total := 0
+	for i := range sizes {
+		sizes[i] = int(r.u32())
+		total += sizes[i]
+	}
+	switch {
+	case r.err != nil:
+		return nil, nil, nil, r.err
+	case len(data)-r.off < total:
+		return nil, nil, nil, ErrInvalidFont
+	}
+	// split streams
+	streams := make([]*reader, len(sizes))
+	for i, size := range sizes {
+		streams[i] = &reader{buf: r.bytes(size)}
+	}
+	nContourStream, nPointsStream, flagStream, glyphStream := streams[0], streams[1], streams[2], streams[3]
+	compositeStream, bboxStream, instructionStream := streams[4], streams[5], streams[6]
+	bboxBitmap := bboxStream.bytes(4 * ((numGlyphs + 31) / 32))
+	var overlapBitmap []byte
+	if optionFlags&1 != 0 {
+		overlapBitmap = r.bytes((numGlyphs + 7) / 8)
+	}
+	if r.err != nil || bboxStream.err != nil {
+		return nil, nil, nil, ErrInvalidFont
+	}
+	// reconstruct glyphs
+	var glyf []byte
+	offsets := make([]int, numGlyphs+1)
+	xMins := make([]int16, numGlyphs)
+	for i := 0; i < numGlyphs; i++ {
+		offsets[i] = len(glyf)
+		nContours := int16(nContourStream.u16())
+		hasBBox := bboxBitmap[i>>3]&(0x80>>(i&7)) != 0
+		var bbox [4]int16
+		if hasBBox {
+			for j := range bbox {
+				bbox[j] = int16(bboxStream.u16())
+			}
+		}
+		switch {
+		case nContours == 0:
+			if hasBBox {
+				return nil, nil, nil, ErrInvalidFont
+			}
+		case nContours > 0:
+			overlap := overlapBitmap != nil && overlapBitmap[i>>3]&(0x80>>(i&7)) != 0
+			g, err := simpleGlyph(int(nContours), hasBBox, bbox, overlap, nPointsStream, flagStream, glyphStream, instructionStream)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			xMins[i] = int16(binary.BigEndian.Uint16(g[2:]))
+			glyf = append(glyf, g...)
+		case nContours == -1:
+			if !hasBBox {
+				return nil, nil, nil, ErrInvalidFont
+			}
+			g, err := compositeGlyph(bbox, compositeStream, glyphStream, instructionStream)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			xMins[i] = bbox[0]
+			glyf = append(glyf, g...)
+		default:
+			return nil, nil, nil, ErrInvalidFont
+		}
+		// pad to 4 bytes
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	offsets[numGlyphs] = len(glyf)
+	for _, s := range streams {
+		if s.err != nil {
+			return nil, nil, nil, ErrInvalidFont
+		}
+	}
+	// build loca
+	var loca []byte
+	for _, offset := range offsets {
+		if indexFormat == 0 {
+			if offset/2 > 0xffff {
+				return nil, nil, nil, ErrInvalidFont
+			}
+			loca = binary.BigEndian.AppendUint16(loca, uint16(offset/2))
+		} else {
+			loca = binary.BigEndian.AppendUint32(loca, uint32(offset))
+		}
+	}
+	return glyf, loca, xMins, nil
+}
+
+// simpleGlyph reconstructs a simple glyph.
+func simpleGlyph(nContours int, hasBBox bool, bbox [4]int16, overlap bool, nPointsStream, flagStream, glyphStream, instructionStream *reader) ([]byte, error) {
+	// end points
+	endPts := make([]uint16, nContours)
+	total := 0
+	for i := range endPts {
+		total += int(nPointsStream.u255())
+		if total == 0 || total > 0xffff {
+			return nil, ErrInvalidFont
+		}
+		endPts[i] = uint16(total - 1)
+	}
+	// points
+	flags := make([]byte, total)
+	xs, ys := make([]int, total), make([]int, total)
+	x, y := 0, 0
+	for i := 0; i < total; i++ {
+		flag := flagStream.u8()
+		onCurve := flag>>7 == 0
+		dx, dy := triplet(flag&0x7f, glyphStream)
+		x, y = x+dx, y+dy
+		xs[i], ys[i] = x, y
+		if onCurve {
+			flags[i] = 0x01
+		}
+	}
+	if overlap && total != 0 {
+		flags[0] |= 0x40
+	}
+	// instructions
+	instructionLength := int(glyphStream.u255())
+	instructions := instructionStream.bytes(instructionLength)
+	if flagStream.err != nil || glyphStream.err != nil || instructionStream.err != nil || nPointsStream.err != nil {
+		return nil, ErrInvalidFont
+	}
+	// bbox
+	if !hasBBox && total != 0 {
+		xMin, yMin, xMax, yMax := xs[0], ys[0], xs[0], ys[0]
+		for i := 1; i < total; i++ {
+			xMin, xMax = minInt(xMin, xs[i]), maxInt(xMax, xs[i])
+			yMin, yMax = minInt(yMin, ys[i]), maxInt(yMax, ys[i])
+		}
+		bbox = [4]int16{int16(xMin), int16(yMin), int16(xMax), int16(yMax)}
+	}
+	// encode
+	g := binary.BigEndian.AppendUint16(nil, uint16(nContours))
+	for _, v := range bbox {
+		g = binary.BigEndian.AppendUint16(g, uint16(v))
+	}
+	for _, v := range endPts {
+		g = binary.BigEndian.AppendUint16(g, v)
+	}
+	g = binary.BigEndian.AppendUint16(g, uint16(instructionLength))
+	g = append(g, instructions...)
+	var xb, yb []byte
+	px, py := 0, 0
+	for i := 0; i < total; i++ {
+		dx, dy := xs[i]-px, ys[i]-py
+		px, py = xs[i], ys[i]
+		switch {
+		case dx == 0:
+			flags[i] |= 0x10
+		case dx > -256 && dx < 256:
+			flags[i] |= 0x02
+			if dx > 0 {
+				flags[i] |= 0x10
+			}
+			xb = append(xb, byte(absInt(dx)))
+		default:
+			xb = binary.BigEndian.AppendUint16(xb, uint16(int16(dx)))
+		}
+		switch {
+		case dy == 0:
+			flags[i] |= 0x20
+		case dy > -256 && dy < 256:
+			flags[i] |= 0x04
+			if dy > 0 {
+				flags[i] |= 0x20
+			}
+			yb = append(yb, byte(absInt(dy)))
+		default:
+			yb = binary.BigEndian.AppendUint16(yb, uint16(int16(dy)))
+		}
+	}
+	g = append(g, flags...)
+	g = append(g, xb...)
+	return append(g, yb...), nil
+}
+
+// triplet decodes a woff2 point triplet.
+func triplet(flag byte, r *reader) (int, int) {
+	f := int(flag)
+	switch {
+	case f < 10:
+		return 0, withSign(f, (f&14)<<7+int(r.u8()))
+	case f < 20:
+		return withSign(f, ((f-10)&14)<<7+int(r.u8())), 0
+	case f < 84:
+		b0, b1 := f-20, int(r.u8())
+		return withSign(f, 1+(b0&0x30)+(b1>>4)), withSign(f>>1, 1+((b0&0x0c)<<2)+(b1&0x0f))
+	case f < 120:
+		b0 := f - 84
+		d := r.bytes(2)
+		return withSign(f, 1+((b0/12)<<8)+int(d[0])), withSign(f>>1, 1+(((b0%12)>>2)<<8)+int(d[1]))
+	case f < 124:
+		d := r.bytes(3)
+		return withSign(f, int(d[0])<<4+int(d[1])>>4), withSign(f>>1, int(d[1]&0x0f)<<8+int(d[2]))
+	}
+	d := r.bytes(4)
+	return withSign(f, int(d[0])<<8+int(d[1])), withSign(f>>1, int(d[2])<<8+int(d[3]))
+}
+
+// withSign returns v, negated when the low bit of flag is not set.
+func withSign(flag, v int) int {
+	if flag&1 != 0 {
+		return v
+	}
+	return -v
+}
+
+// Composite glyph flags.
+const (
+	argsAreWords     = 0x0001
+	haveScale        = 0x0008
+	moreComponents   = 0x0020
+	haveXYScale      = 0x0040
+	haveTwoByTwo     = 0x0080
+	haveInstructions = 0x0100
+)
+
+// compositeGlyph reconstructs a composite glyph.
+func compositeGlyph(bbox [4]int16, compositeStream, glyphStream, instructionStream *reader) ([]byte, error) {
+	// determine composite data size
+	start := compositeStream.off
+	var instructions bool
+	for {
+		flags := compositeStream.u16()
+		n := 4 // flags, glyph index
+		if flags&argsAreWords != 0 {
+			n += 4
+		} else {
+			n += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			n += 2
+		case flags&haveXYScale != 0:
+			n += 4
+		case flags&haveTwoByTwo != 0:
+			n += 8
+		}
+		compositeStream.skip(n - 2)
+		if flags&haveInstructions != 0 {
+			instructions = true
+		}
+		if compositeStream.err != nil {
+			return nil, ErrInvalidFont
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	// encode
+	g := binary.BigEndian.AppendUint16(nil, 0xffff)
+	for _, v := range bbox {
+		g = binary.BigEndian.AppendUint16(g, uint16(v))
+	}
+	g = append(g, compositeStream.buf[start:compositeStream.off]...)
+	if instructions {
+		n := int(glyphStream.u255())
+		g = binary.BigEndian.AppendUint16(g, uint16(n))
+		g = append(g, instructionStream.bytes(n)...)
+		if glyphStream.err != nil || instructionStream.err != nil {
+			return nil, ErrInvalidFont
+		}
+	}
+	return g, nil
+}
+
+// minInt returns the minimum of a, b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the maximum of a, b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// absInt returns the absolute value of v.
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,221 @@
+package convert
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// FontInfo describes a font file.
+type FontInfo struct {
+	// Format is the font file format ("woff2", "woff", "ttf", "otf").
+	Format string `json:"format"`
+	// Family is the font family name (typographic family name when present).
+	Family string `json:"family,omitempty"`
+	// Subfamily is the font subfamily name (typographic subfamily name when
+	// present), ie "Regular", "Bold Italic".
+	Subfamily string `json:"subfamily,omitempty"`
+	// FullName is the full font name.
+	FullName string `json:"fullName,omitempty"`
+	// PostScriptName is the postscript font name.
+	PostScriptName string `json:"postScriptName,omitempty"`
+	// Version is the font version (ie, "3.019").
+	Version string `json:"version,omitempty"`
+	// Glyphs is the number of glyphs in the font.
+	Glyphs int `json:"glyphs"`
+	// Runes are the sorted runes mapped by the font's cmap.
+	Runes []rune `json:"-"`
+	// Axes are the variable font axes.
+	Axes []Axis `json:"axes,omitempty"`
+}
+
+// Axis describes a variable font axis.
+type Axis struct {
+	Tag     string  `json:"tag"`
+	Name    string  `json:"name,omitempty"`
+	Min     float64 `json:"min"`
+	Default float64 `json:"default"`
+	Max     float64 `json:"max"`
+}
+
+// Has returns true when the font maps the rune.
+func (info *FontInfo) Has(r rune) bool {
+	i := sort.Search(len(info.Runes), func(i int) bool {
+		return info.Runes[i] >= r
+	})
+	return i < len(info.Runes) && info.Runes[i] == r
+}
+
+// HasRange returns true when the font maps any rune in the range lo..hi
+// (inclusive).
+func (info *FontInfo) HasRange(lo, hi rune) bool {
+	i := sort.Search(len(info.Runes), func(i int) bool {
+		return info.Runes[i] >= lo
+	})
+	return i < len(info.Runes) && info.Runes[i] <= hi
+}
+
+// Variable returns true when the font has variable font axes.
+func (info *FontInfo) Variable() bool {
+	return len(info.Axes) != 0
+}
+
+// Inspect reads the woff2, woff, ttf, or otf font data from the reader,
+// returning the font's names, version, mapped runes, and variable axes.
+func Inspect(r io.Reader) (*FontInfo, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return InspectBytes(buf)
+}
+
+// InspectBytes inspects the woff2, woff, ttf, or otf font data. See Inspect.
+func InspectBytes(buf []byte) (*FontInfo, error) {
+	info := &FontInfo{
+		Format: Format(buf),
+	}
+	// decompress to sfnt
+	var err error
+	switch info.Format {
+	case "woff2":
+		if buf, err = WOFF2ToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "woff":
+		if buf, err = WOFFToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "ttf", "otf":
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	_, tables, err := readSFNT(buf)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string][]byte)
+	for _, t := range tables {
+		m[t.tag] = t.data
+	}
+	// names
+	names := readNames(m["name"])
+	info.Family = firstName(names, 16, 1)
+	info.Subfamily = firstName(names, 17, 2)
+	info.FullName = names[4]
+	info.PostScriptName = names[6]
+	info.Version = strings.TrimSpace(strings.TrimPrefix(names[5], "Version "))
+	if i := strings.IndexByte(info.Version, ';'); i != -1 {
+		info.Version = info.Version[:i]
+	}
+	// glyphs
+	if maxp := m["maxp"]; len(maxp) >= 6 {
+		info.Glyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+	}
+	// runes
+	if data, ok := m["cmap"]; ok {
+		cm, err := readCmap(data)
+		if err != nil {
+			return nil, err
+		}
+		info.Runes = cm.runes()
+	}
+	// axes
+	if data, ok := m["fvar"]; ok {
+		if info.Axes, err = readFvar(data, names); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+// readNames reads the name table, returning the names by name id. Windows
+// english names are preferred over other names.
+func readNames(data []byte) map[uint16]string {
+	names := make(map[uint16]string)
+	scores := make(map[uint16]int)
+	r := &reader{buf: data}
+	r.skip(2) // version
+	n := int(r.u16())
+	storage := int(r.u16())
+	for i := 0; i < n && r.err == nil; i++ {
+		platformID, encodingID, languageID := r.u16(), r.u16(), r.u16()
+		nameID, length, offset := r.u16(), int(r.u16()), int(r.u16())
+		if r.err != nil || storage+offset+length > len(data) {
+			break
+		}
+		b := data[storage+offset : storage+offset+length]
+		score, s := 0, ""
+		switch {
+		case platformID == 3 && (encodingID == 1 || encodingID == 10):
+			score, s = 2, decodeUTF16(b)
+			if languageID == 0x409 {
+				score = 3
+			}
+		case platformID == 0:
+			score, s = 1, decodeUTF16(b)
+		case platformID == 1 && encodingID == 0:
+			// mac roman, treated as latin-1
+			runes := make([]rune, len(b))
+			for j, c := range b {
+				runes[j] = rune(c)
+			}
+			score, s = 1, string(runes)
+		}
+		if score > scores[nameID] {
+			names[nameID], scores[nameID] = s, score
+		}
+	}
+	return names
+}
+
+// decodeUTF16 decodes big endian utf-16 data.
+func decodeUTF16(b []byte) string {
+	v := make([]uint16, len(b)/2)
+	for i := range v {
+		v[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(v))
+}
+
+// firstName returns the first non-empty name for the name ids.
+func firstName(names map[uint16]string, ids ...uint16) string {
+	for _, id := range ids {
+		if s := names[id]; s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// readFvar reads the variable font axes from the fvar table.
+func readFvar(data []byte, names map[uint16]string) ([]Axis, error) {
+	r := &reader{buf: data}
+	r.skip(4) // version
+	offset := int(r.u16())
+	r.skip(2) // reserved
+	n, size := int(r.u16()), int(r.u16())
+	if r.err != nil || size < 20 || offset+n*size > len(data) {
+		return nil, ErrInvalidFont
+	}
+	axes := make([]Axis, n)
+	for i := range axes {
+		rec := data[offset+i*size:]
+		axes[i] = Axis{
+			Tag:     string(rec[:4]),
+			Min:     fixed(rec[4:]),
+			Default: fixed(rec[8:]),
+			Max:     fixed(rec[12:]),
+			Name:    names[binary.BigEndian.Uint16(rec[18:])],
+		}
+	}
+	return axes, nil
+}
+
+// fixed reads a 16.16 fixed point number.
+func fixed(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
@@ -0,0 +1,106 @@
+package convert
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// table is a sfnt table.
+type table struct {
+	tag      string
+	checksum uint32
+	data     []byte
+}
+
+// readSFNT reads the tables from the sfnt font data.
+func readSFNT(buf []byte) (uint32, []table, error) {
+	if len(buf) < 12 {
+		return 0, nil, ErrInvalidFont
+	}
+	flavor := binary.BigEndian.Uint32(buf)
+	if flavor == 0x74746366 {
+		return 0, nil, ErrCollectionUnsupported
+	}
+	n := int(binary.BigEndian.Uint16(buf[4:]))
+	if len(buf) < 12+16*n {
+		return 0, nil, ErrInvalidFont
+	}
+	tables := make([]table, n)
+	for i := 0; i < n; i++ {
+		rec := buf[12+16*i:]
+		offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(offset)+uint64(length) > uint64(len(buf)) {
+			return 0, nil, ErrInvalidFont
+		}
+		tables[i] = table{
+			tag:      string(rec[:4]),
+			checksum: binary.BigEndian.Uint32(rec[4:]),
+			data:     buf[offset : offset+length],
+		}
+	}
+	return flavor, tables, nil
+}
+
+// writeSFNT writes the sfnt font data for the tables, sorting the tables by
+// tag, and recalculating table checksums and the head checksum adjustment.
+func writeSFNT(flavor uint32, tables []table) []byte {
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].tag < tables[j].tag
+	})
+	n := len(tables)
+	// calculate size
+	size := 12 + 16*n
+	for _, t := range tables {
+		size += pad4(len(t.data))
+	}
+	buf := make([]byte, size)
+	// offset table
+	binary.BigEndian.PutUint32(buf, flavor)
+	binary.BigEndian.PutUint16(buf[4:], uint16(n))
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		entrySelector++
+	}
+	binary.BigEndian.PutUint16(buf[6:], uint16(searchRange*16))
+	binary.BigEndian.PutUint16(buf[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(buf[10:], uint16(n*16-searchRange*16))
+	// tables
+	headOffset := -1
+	offset := 12 + 16*n
+	for i, t := range tables {
+		data := buf[offset : offset+len(t.data)]
+		copy(data, t.data)
+		if t.tag == "head" && len(data) >= 12 {
+			binary.BigEndian.PutUint32(data[8:], 0)
+			headOffset = offset
+		}
+		rec := buf[12+16*i:]
+		copy(rec, t.tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(offset))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t.data)))
+		offset += pad4(len(t.data))
+	}
+	// head checksum adjustment
+	if headOffset != -1 {
+		binary.BigEndian.PutUint32(buf[headOffset+8:], 0xb1b0afba-checksum(buf))
+	}
+	return buf
+}
+
+// checksum calculates the sfnt checksum of the data.
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var v [4]byte
+		copy(v[:], data[i:])
+		sum += binary.BigEndian.Uint32(v[:])
+	}
+	return sum
+}
+
+// pad4 returns n padded to a multiple of 4.
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
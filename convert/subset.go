@@ -0,0 +1,623 @@
+package convert
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SubsetOption is a font subsetting option.
+type SubsetOption func(*subsetOptions)
+
+// subsetOptions are font subsetting options.
+type subsetOptions struct {
+	runes  map[rune]bool
+	ranges []string
+	err    error
+}
+
+// add adds the runes to the options.
+func (o *subsetOptions) add(runes ...rune) {
+	for _, r := range runes {
+		o.runes[r] = true
+	}
+}
+
+// WithRunes is a subset option to retain the glyphs for the runes.
+func WithRunes(runes ...rune) SubsetOption {
+	return func(o *subsetOptions) {
+		o.add(runes...)
+	}
+}
+
+// WithText is a subset option to retain the glyphs for the runes in the text.
+func WithText(text string) SubsetOption {
+	return func(o *subsetOptions) {
+		o.add([]rune(text)...)
+	}
+}
+
+// WithUnicodeRange is a subset option to retain the glyphs for the css
+// unicode-range values (ie, "U+0000-00FF", "U+0131", "U+4??"), such as a
+// font face's Range.
+func WithUnicodeRange(ranges ...string) SubsetOption {
+	return func(o *subsetOptions) {
+		o.ranges = append(o.ranges, ranges...)
+	}
+}
+
+// WithSubsets is a subset option to retain the glyphs for the named subsets
+// (ie, "latin", "latin-ext", "cyrillic"). See Subsets for the known subset
+// names.
+func WithSubsets(names ...string) SubsetOption {
+	return func(o *subsetOptions) {
+		for _, name := range names {
+			ranges, ok := subsets[name]
+			if !ok {
+				o.err = ErrUnknownSubset
+				return
+			}
+			o.ranges = append(o.ranges, ranges...)
+		}
+	}
+}
+
+// Subsets returns the known subset names.
+func Subsets() []string {
+	var names []string
+	for name := range subsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SubsetRanges returns the unicode ranges (ie, "U+0000-00FF") for the named
+// subset.
+func SubsetRanges(name string) ([]string, bool) {
+	ranges, ok := subsets[name]
+	return append([]string(nil), ranges...), ok
+}
+
+// TextSubsets returns the named subsets needed to render the text, and any
+// runes in the text not in a known subset. Subsets are chosen in the order
+// latin, latin-ext, then the remaining subsets in name order, skipping
+// subsets whose runes are already covered.
+func TextSubsets(text string) ([]string, []rune) {
+	names := []string{"latin", "latin-ext"}
+	for _, name := range Subsets() {
+		if name != "latin" && name != "latin-ext" {
+			names = append(names, name)
+		}
+	}
+	var needed []string
+	var missing []rune
+	seen := make(map[rune]bool)
+	for _, r := range text {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		covered := false
+		for _, name := range needed {
+			if covered = subsetContains(name, r); covered {
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+		for _, name := range names {
+			if covered = subsetContains(name, r); covered {
+				needed = append(needed, name)
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, r)
+		}
+	}
+	return needed, missing
+}
+
+// subsetContains returns true when the named subset contains the rune.
+func subsetContains(name string, r rune) bool {
+	for _, s := range subsets[name] {
+		if lo, hi, err := ParseUnicodeRange(s); err == nil && lo <= r && r <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+// Subset subsets the woff2, woff, or ttf font data, retaining only the glyphs
+// for the runes specified by the options (and any glyphs they reference).
+// Subsetted woff2 and woff font data is returned as woff, and ttf as ttf.
+//
+// Glyph ids are retained: unused glyphs are emptied, and the cmap is rewritten
+// to only map the retained runes. Only TrueType (glyf) outlines are supported.
+func Subset(buf []byte, opts ...SubsetOption) ([]byte, error) {
+	o := &subsetOptions{
+		runes: make(map[rune]bool),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.err != nil {
+		return nil, o.err
+	}
+	for _, s := range o.ranges {
+		lo, hi, err := ParseUnicodeRange(s)
+		if err != nil {
+			return nil, err
+		}
+		for r := lo; r <= hi; r++ {
+			o.runes[r] = true
+		}
+	}
+	// decompress to sfnt
+	from := Format(buf)
+	var err error
+	switch from {
+	case "woff2":
+		if buf, err = WOFF2ToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "woff":
+		if buf, err = WOFFToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "ttf":
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	flavor, tables, err := readSFNT(buf)
+	if err != nil {
+		return nil, err
+	}
+	if buf, err = subsetTables(flavor, tables, o.runes); err != nil {
+		return nil, err
+	}
+	if from != "ttf" {
+		return SFNTToWOFF(buf)
+	}
+	return buf, nil
+}
+
+// subsetTables subsets the sfnt tables, returning the sfnt font data.
+func subsetTables(flavor uint32, tables []table, runes map[rune]bool) ([]byte, error) {
+	m := make(map[string]int)
+	for i, t := range tables {
+		m[t.tag] = i
+	}
+	for _, tag := range []string{"cmap", "glyf", "head", "loca", "maxp"} {
+		if _, ok := m[tag]; !ok {
+			return nil, ErrUnsupportedFormat
+		}
+	}
+	head, maxp := tables[m["head"]].data, tables[m["maxp"]].data
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, ErrInvalidFont
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	offsets, err := readLoca(tables[m["loca"]].data, numGlyphs, binary.BigEndian.Uint16(head[50:]) != 0)
+	if err != nil {
+		return nil, err
+	}
+	glyf := tables[m["glyf"]].data
+	if offsets[numGlyphs] > uint32(len(glyf)) {
+		return nil, ErrInvalidFont
+	}
+	// map runes
+	cm, err := readCmap(tables[m["cmap"]].data)
+	if err != nil {
+		return nil, err
+	}
+	mapping := make(map[rune]uint16)
+	keep := map[uint16]bool{0: true}
+	for r := range runes {
+		if gid := cm.lookup(r); gid != 0 && int(gid) < numGlyphs {
+			mapping[r], keep[gid] = gid, true
+		}
+	}
+	// add composite glyph components
+	queue := make([]uint16, 0, len(keep))
+	for gid := range keep {
+		queue = append(queue, gid)
+	}
+	for len(queue) != 0 {
+		gid := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, c := range components(glyf[offsets[gid]:offsets[gid+1]]) {
+			if !keep[c] && int(c) < numGlyphs {
+				keep[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+	// build glyf, loca
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		if keep[uint16(gid)] {
+			newGlyf = append(newGlyf, glyf[offsets[gid]:offsets[gid+1]]...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+		binary.BigEndian.PutUint32(newLoca[4*(gid+1):], uint32(len(newGlyf)))
+	}
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+	// build tables
+	var out []table
+	for _, t := range tables {
+		switch t.tag {
+		case "glyf":
+			t.data = newGlyf
+		case "loca":
+			t.data = newLoca
+		case "head":
+			t.data = newHead
+		case "cmap":
+			t.data = writeCmap(mapping)
+		case "DSIG", "hdmx", "LTSH", "VDMX":
+			continue
+		}
+		out = append(out, t)
+	}
+	return writeSFNT(flavor, out), nil
+}
+
+// readLoca reads the glyph offsets from the loca table.
+func readLoca(data []byte, numGlyphs int, long bool) ([]uint32, error) {
+	offsets := make([]uint32, numGlyphs+1)
+	size := 2
+	if long {
+		size = 4
+	}
+	if len(data) < size*(numGlyphs+1) {
+		return nil, ErrInvalidFont
+	}
+	for i := range offsets {
+		if long {
+			offsets[i] = binary.BigEndian.Uint32(data[4*i:])
+		} else {
+			offsets[i] = 2 * uint32(binary.BigEndian.Uint16(data[2*i:]))
+		}
+		if i != 0 && offsets[i] < offsets[i-1] {
+			return nil, ErrInvalidFont
+		}
+	}
+	return offsets, nil
+}
+
+// components returns the component glyph ids of a composite glyph.
+func components(glyph []byte) []uint16 {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+	var gids []uint16
+	r := &reader{buf: glyph, off: 10}
+	for {
+		flags := r.u16()
+		gids = append(gids, r.u16())
+		switch {
+		case flags&0x0001 != 0: // ARG_1_AND_2_ARE_WORDS
+			r.skip(4)
+		default:
+			r.skip(2)
+		}
+		switch {
+		case flags&0x0008 != 0: // WE_HAVE_A_SCALE
+			r.skip(2)
+		case flags&0x0040 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			r.skip(4)
+		case flags&0x0080 != 0: // WE_HAVE_A_TWO_BY_TWO
+			r.skip(8)
+		}
+		if r.err != nil {
+			return gids[:len(gids)-1]
+		}
+		if flags&0x0020 == 0 { // MORE_COMPONENTS
+			return gids
+		}
+	}
+}
+
+// cmap is a unicode cmap subtable.
+type cmap struct {
+	// ranges are the mapped rune ranges (inclusive).
+	ranges [][2]rune
+	// lookup returns the glyph id for a rune.
+	lookup func(rune) uint16
+}
+
+// runes returns the sorted runes mapped to a glyph.
+func (cm *cmap) runes() []rune {
+	var runes []rune
+	for _, rng := range cm.ranges {
+		for r := rng[0]; r <= rng[1]; r++ {
+			if cm.lookup(r) != 0 {
+				runes = append(runes, r)
+			}
+		}
+	}
+	return runes
+}
+
+// readCmap reads the cmap table, using the best available unicode subtable.
+func readCmap(data []byte) (*cmap, error) {
+	r := &reader{buf: data}
+	r.skip(2)
+	n := int(r.u16())
+	best, bestScore := -1, 0
+	for i := 0; i < n; i++ {
+		platformID, encodingID, offset := r.u16(), r.u16(), int(r.u32())
+		if r.err != nil || offset+2 > len(data) {
+			return nil, ErrInvalidFont
+		}
+		format := binary.BigEndian.Uint16(data[offset:])
+		score := 0
+		switch {
+		case format == 12 && (platformID == 0 || platformID == 3 && encodingID == 10):
+			score = 3
+		case format == 4 && (platformID == 0 || platformID == 3 && encodingID == 1):
+			score = 2
+		case format == 4 && platformID == 3 && encodingID == 0:
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = offset, score
+		}
+	}
+	switch {
+	case best == -1:
+		return nil, ErrUnsupportedFormat
+	case bestScore == 3:
+		return readCmap12(data[best:])
+	}
+	return readCmap4(data[best:])
+}
+
+// readCmap4 reads a format 4 cmap subtable.
+func readCmap4(data []byte) (*cmap, error) {
+	if len(data) < 14 {
+		return nil, ErrInvalidFont
+	}
+	segCount := int(binary.BigEndian.Uint16(data[6:])) / 2
+	if len(data) < 16+8*segCount {
+		return nil, ErrInvalidFont
+	}
+	ends, starts := data[14:], data[16+2*segCount:]
+	deltas, rangeOffsets := data[16+4*segCount:], 16+6*segCount
+	cm := new(cmap)
+	for i := 0; i < segCount; i++ {
+		start, end := rune(binary.BigEndian.Uint16(starts[2*i:])), rune(binary.BigEndian.Uint16(ends[2*i:]))
+		if start <= end && start != 0xffff {
+			cm.ranges = append(cm.ranges, [2]rune{start, end})
+		}
+	}
+	cm.lookup = func(c rune) uint16 {
+		if c > 0xffff {
+			return 0
+		}
+		i := sort.Search(segCount, func(i int) bool {
+			return rune(binary.BigEndian.Uint16(ends[2*i:])) >= c
+		})
+		if i == segCount {
+			return 0
+		}
+		start := rune(binary.BigEndian.Uint16(starts[2*i:]))
+		if c < start {
+			return 0
+		}
+		delta := binary.BigEndian.Uint16(deltas[2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(data[rangeOffsets+2*i:]))
+		if rangeOffset == 0 {
+			return uint16(c) + delta
+		}
+		off := rangeOffsets + 2*i + rangeOffset + 2*int(c-start)
+		if off+2 > len(data) {
+			return 0
+		}
+		if gid := binary.BigEndian.Uint16(data[off:]); gid != 0 {
+			return gid + delta
+		}
+		return 0
+	}
+	return cm, nil
+}
+
+// readCmap12 reads a format 12 cmap subtable.
+func readCmap12(data []byte) (*cmap, error) {
+	if len(data) < 16 {
+		return nil, ErrInvalidFont
+	}
+	n := int(binary.BigEndian.Uint32(data[12:]))
+	if len(data) < 16+12*n {
+		return nil, ErrInvalidFont
+	}
+	groups := data[16:]
+	cm := new(cmap)
+	for i := 0; i < n; i++ {
+		start, end := rune(binary.BigEndian.Uint32(groups[12*i:])), rune(binary.BigEndian.Uint32(groups[12*i+4:]))
+		if start <= end && end <= 0x10ffff {
+			cm.ranges = append(cm.ranges, [2]rune{start, end})
+		}
+	}
+	cm.lookup = func(c rune) uint16 {
+		i := sort.Search(n, func(i int) bool {
+			return rune(binary.BigEndian.Uint32(groups[12*i+4:])) >= c
+		})
+		if i == n {
+			return 0
+		}
+		start := rune(binary.BigEndian.Uint32(groups[12*i:]))
+		if c < start {
+			return 0
+		}
+		return uint16(binary.BigEndian.Uint32(groups[12*i+8:]) + uint32(c-start))
+	}
+	return cm, nil
+}
+
+// writeCmap writes a cmap table for the rune to glyph id mapping, containing a
+// format 4 (BMP) subtable, and a format 12 subtable.
+func writeCmap(mapping map[rune]uint16) []byte {
+	runes := make([]rune, 0, len(mapping))
+	for r := range mapping {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool {
+		return runes[i] < runes[j]
+	})
+	// build segments of consecutive runes and glyph ids
+	type segment struct {
+		start, end rune
+		gid        uint16
+	}
+	var segs []segment
+	for _, r := range runes {
+		if n := len(segs); n != 0 && segs[n-1].end+1 == r && rune(segs[n-1].gid)+r-segs[n-1].start == rune(mapping[r]) {
+			segs[n-1].end = r
+			continue
+		}
+		segs = append(segs, segment{r, r, mapping[r]})
+	}
+	// format 4
+	var bmp []segment
+	for _, s := range segs {
+		if s.start > 0xffff {
+			break
+		}
+		if s.end > 0xffff {
+			s.end = 0xffff
+		}
+		bmp = append(bmp, s)
+	}
+	if n := len(bmp); n == 0 || bmp[n-1].end != 0xffff {
+		bmp = append(bmp, segment{0xffff, 0xffff, 0})
+	}
+	segCount := len(bmp)
+	f4 := make([]byte, 16+8*segCount)
+	binary.BigEndian.PutUint16(f4, 4)
+	binary.BigEndian.PutUint16(f4[2:], uint16(len(f4)))
+	binary.BigEndian.PutUint16(f4[6:], uint16(2*segCount))
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= segCount {
+		searchRange *= 2
+		entrySelector++
+	}
+	binary.BigEndian.PutUint16(f4[8:], uint16(2*searchRange))
+	binary.BigEndian.PutUint16(f4[10:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(f4[12:], uint16(2*segCount-2*searchRange))
+	for i, s := range bmp {
+		binary.BigEndian.PutUint16(f4[14+2*i:], uint16(s.end))
+		binary.BigEndian.PutUint16(f4[16+2*segCount+2*i:], uint16(s.start))
+		delta := uint16(1)
+		if s.gid != 0 {
+			delta = s.gid - uint16(s.start)
+		}
+		binary.BigEndian.PutUint16(f4[16+4*segCount+2*i:], delta)
+	}
+	// format 12
+	f12 := make([]byte, 16+12*len(segs))
+	binary.BigEndian.PutUint16(f12, 12)
+	binary.BigEndian.PutUint32(f12[4:], uint32(len(f12)))
+	binary.BigEndian.PutUint32(f12[12:], uint32(len(segs)))
+	for i, s := range segs {
+		binary.BigEndian.PutUint32(f12[16+12*i:], uint32(s.start))
+		binary.BigEndian.PutUint32(f12[20+12*i:], uint32(s.end))
+		binary.BigEndian.PutUint32(f12[24+12*i:], uint32(s.gid))
+	}
+	// header, encoding records
+	buf := make([]byte, 4+3*8)
+	binary.BigEndian.PutUint16(buf[2:], 3)
+	for i, rec := range []struct {
+		platformID, encodingID uint16
+		offset                 int
+	}{
+		{0, 3, len(buf)},
+		{3, 1, len(buf)},
+		{3, 10, len(buf) + len(f4)},
+	} {
+		binary.BigEndian.PutUint16(buf[4+8*i:], rec.platformID)
+		binary.BigEndian.PutUint16(buf[6+8*i:], rec.encodingID)
+		binary.BigEndian.PutUint32(buf[8+8*i:], uint32(rec.offset))
+	}
+	return append(append(buf, f4...), f12...)
+}
+
+// ParseUnicodeRange parses a css unicode-range value (ie, "U+0000-00FF",
+// "U+0131", "U+4??").
+func ParseUnicodeRange(s string) (rune, rune, error) {
+	v := strings.TrimSpace(s)
+	if len(v) < 3 || (v[:2] != "U+" && v[:2] != "u+") {
+		return 0, 0, ErrInvalidUnicodeRange
+	}
+	v = v[2:]
+	var lo, hi string
+	switch i := strings.IndexByte(v, '-'); {
+	case i != -1:
+		lo, hi = v[:i], v[i+1:]
+	case strings.HasSuffix(v, "?"):
+		lo, hi = strings.ReplaceAll(v, "?", "0"), strings.ReplaceAll(v, "?", "F")
+	default:
+		lo, hi = v, v
+	}
+	a, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, 0, ErrInvalidUnicodeRange
+	}
+	b, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil || b < a || b > 0x10ffff {
+		return 0, 0, ErrInvalidUnicodeRange
+	}
+	return rune(a), rune(b), nil
+}
+
+// subsets are the unicode ranges for named subsets, as used by Google Fonts.
+var subsets = map[string][]string{
+	"latin": {
+		"U+0000-00FF", "U+0131", "U+0152-0153", "U+02BB-02BC", "U+02C6",
+		"U+02DA", "U+02DC", "U+0304", "U+0308", "U+0329", "U+2000-206F",
+		"U+20AC", "U+2122", "U+2191", "U+2193", "U+2212", "U+2215", "U+FEFF",
+		"U+FFFD",
+	},
+	"latin-ext": {
+		"U+0100-02BA", "U+02BD-02C5", "U+02C7-02CC", "U+02CE-02D7",
+		"U+02DD-02FF", "U+0304", "U+0308", "U+0329", "U+1D00-1DBF",
+		"U+1E00-1E9F", "U+1EF2-1EFF", "U+2020", "U+20A0-20AB", "U+20AD-20C0",
+		"U+2113", "U+2C60-2C7F", "U+A720-A7FF",
+	},
+	"cyrillic": {
+		"U+0301", "U+0400-045F", "U+0490-0491", "U+04B0-04B1", "U+2116",
+	},
+	"cyrillic-ext": {
+		"U+0460-052F", "U+1C80-1C88", "U+20B4", "U+2DE0-2DFF", "U+A640-A69F",
+		"U+FE2E-FE2F",
+	},
+	"greek": {
+		"U+0370-0377", "U+037A-037F", "U+0384-038A", "U+038C", "U+038E-03A1",
+		"U+03A3-03FF",
+	},
+	"greek-ext": {
+		"U+1F00-1FFF",
+	},
+	"vietnamese": {
+		"U+0102-0103", "U+0110-0111", "U+0128-0129", "U+0168-0169",
+		"U+01A0-01A1", "U+01AF-01B0", "U+0300-0301", "U+0303-0304",
+		"U+0308-0309", "U+0323", "U+0329", "U+1EA0-1EF9", "U+20AB",
+	},
+	"hebrew": {
+		"U+0307-0308", "U+0590-05FF", "U+200C-2010", "U+20AA", "U+25CC",
+		"U+FB1D-FB4F",
+	},
+	"arabic": {
+		"U+0600-06FF", "U+0750-077F", "U+0870-088E", "U+0890-0891",
+		"U+0898-08E1", "U+08E3-08FF", "U+200C-200E", "U+2010-2011", "U+204F",
+		"U+2E41", "U+FB50-FDFF", "U+FE70-FE74", "U+FE76-FEFC",
+	},
+}
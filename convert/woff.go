@@ -0,0 +1,106 @@
+package convert
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io/ioutil"
+)
+
+// WOFFToSFNT decompresses woff font data, returning the sfnt (ttf/otf) font
+// data.
+func WOFFToSFNT(buf []byte) ([]byte, error) {
+	if len(buf) < 44 || string(buf[:4]) != "wOFF" {
+		return nil, ErrInvalidFont
+	}
+	flavor := binary.BigEndian.Uint32(buf[4:])
+	n := int(binary.BigEndian.Uint16(buf[12:]))
+	if len(buf) < 44+20*n {
+		return nil, ErrInvalidFont
+	}
+	tables := make([]table, n)
+	for i := 0; i < n; i++ {
+		rec := buf[44+20*i:]
+		offset := binary.BigEndian.Uint32(rec[4:])
+		compLength := binary.BigEndian.Uint32(rec[8:])
+		origLength := binary.BigEndian.Uint32(rec[12:])
+		if uint64(offset)+uint64(compLength) > uint64(len(buf)) || compLength > origLength {
+			return nil, ErrInvalidFont
+		}
+		data := buf[offset : offset+compLength]
+		if compLength < origLength {
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			if data, err = ioutil.ReadAll(r); err != nil {
+				return nil, err
+			}
+			if uint32(len(data)) != origLength {
+				return nil, ErrInvalidFont
+			}
+		}
+		tables[i] = table{
+			tag:  string(rec[:4]),
+			data: data,
+		}
+	}
+	return writeSFNT(flavor, tables), nil
+}
+
+// SFNTToWOFF compresses sfnt (ttf/otf) font data, returning the woff font
+// data.
+func SFNTToWOFF(buf []byte) ([]byte, error) {
+	flavor, tables, err := readSFNT(buf)
+	if err != nil {
+		return nil, err
+	}
+	// normalize tables (recalculating checksums)
+	sfnt := writeSFNT(flavor, tables)
+	if _, tables, err = readSFNT(sfnt); err != nil {
+		return nil, err
+	}
+	n := len(tables)
+	// compress tables
+	comp := make([][]byte, n)
+	for i, t := range tables {
+		b := new(bytes.Buffer)
+		w, _ := zlib.NewWriterLevel(b, zlib.BestCompression)
+		if _, err := w.Write(t.data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		comp[i] = t.data
+		if b.Len() < len(t.data) {
+			comp[i] = b.Bytes()
+		}
+	}
+	// calculate size
+	size := 44 + 20*n
+	for _, c := range comp {
+		size += pad4(len(c))
+	}
+	out := make([]byte, size)
+	// header
+	copy(out, "wOFF")
+	binary.BigEndian.PutUint32(out[4:], flavor)
+	binary.BigEndian.PutUint32(out[8:], uint32(size))
+	binary.BigEndian.PutUint16(out[12:], uint16(n))
+	binary.BigEndian.PutUint32(out[16:], uint32(len(sfnt)))
+	binary.BigEndian.PutUint16(out[20:], 1)
+	// tables
+	offset := 44 + 20*n
+	for i, t := range tables {
+		rec := out[44+20*i:]
+		copy(rec, t.tag)
+		binary.BigEndian.PutUint32(rec[4:], uint32(offset))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(comp[i])))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t.data)))
+		binary.BigEndian.PutUint32(rec[16:], t.checksum)
+		copy(out[offset:], comp[i])
+		offset += pad4(len(comp[i]))
+	}
+	return out, nil
+}
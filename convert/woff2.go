@@ -0,0 +1,258 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"github.com/andybalholm/brotli"
+)
+
+// WOFF2ToSFNT decompresses woff2 font data, returning the sfnt (ttf/otf) font
+// data. Transformed glyf, loca, and hmtx tables are reconstructed.
+func WOFF2ToSFNT(buf []byte) ([]byte, error) {
+	r := &reader{buf: buf}
+	// header
+	if len(buf) < 48 || string(buf[:4]) != "wOF2" {
+		return nil, ErrInvalidFont
+	}
+	r.skip(4)
+	flavor := r.u32()
+	r.skip(4) // length
+	n := int(r.u16())
+	r.skip(2) // reserved
+	r.skip(4) // totalSfntSize
+	compressedSize := int(r.u32())
+	r.skip(24) // version, metadata, private data
+	if flavor == 0x74746366 {
+		return nil, ErrCollectionUnsupported
+	}
+	// table directory
+	type entry struct {
+		tag         string
+		origLength  uint32
+		length      uint32
+		transformed bool
+	}
+	entries := make([]entry, n)
+	for i := range entries {
+		flags := r.u8()
+		tag := knownTags[flags&0x3f]
+		if flags&0x3f == 0x3f {
+			tag = string(r.bytes(4))
+		}
+		version := flags >> 6
+		e := entry{
+			tag:        tag,
+			origLength: r.base128(),
+		}
+		e.length = e.origLength
+		switch {
+		case tag == "glyf" || tag == "loca":
+			e.transformed = version == 0
+		default:
+			e.transformed = version != 0
+		}
+		if e.transformed {
+			e.length = r.base128()
+		}
+		entries[i] = e
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	// decompress, limited to the declared table lengths
+	if compressedSize > len(buf)-r.off {
+		return nil, ErrInvalidFont
+	}
+	var total int64
+	for _, e := range entries {
+		total += int64(e.length)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(buf[r.off:r.off+compressedSize])), total+1))
+	switch {
+	case err != nil:
+		return nil, err
+	case int64(len(data)) > total:
+		return nil, ErrInvalidFont
+	}
+	// split tables
+	tables := make(map[string][]byte, n)
+	var order []string
+	offset := 0
+	for _, e := range entries {
+		if uint64(offset)+uint64(e.length) > uint64(len(data)) {
+			return nil, ErrInvalidFont
+		}
+		tables[e.tag] = data[offset : offset+int(e.length)]
+		order = append(order, e.tag)
+		offset += int(e.length)
+	}
+	// reconstruct transformed tables
+	var xMins []int16
+	for _, e := range entries {
+		if !e.transformed {
+			continue
+		}
+		switch e.tag {
+		case "glyf":
+			glyf, loca, mins, err := reconstructGlyf(tables["glyf"])
+			if err != nil {
+				return nil, err
+			}
+			tables["glyf"], tables["loca"], xMins = glyf, loca, mins
+		case "loca":
+		case "hmtx":
+		default:
+			return nil, ErrInvalidFont
+		}
+	}
+	for _, e := range entries {
+		if e.tag == "hmtx" && e.transformed {
+			hmtx, err := reconstructHmtx(tables["hmtx"], tables["hhea"], xMins)
+			if err != nil {
+				return nil, err
+			}
+			tables["hmtx"] = hmtx
+		}
+	}
+	// build
+	v := make([]table, len(order))
+	for i, tag := range order {
+		v[i] = table{
+			tag:  tag,
+			data: tables[tag],
+		}
+	}
+	return writeSFNT(flavor, v), nil
+}
+
+// reconstructHmtx reconstructs a transformed hmtx table.
+func reconstructHmtx(data, hhea []byte, xMins []int16) ([]byte, error) {
+	if len(hhea) < 36 || xMins == nil {
+		return nil, ErrInvalidFont
+	}
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	numGlyphs := len(xMins)
+	if numHMetrics < 1 || numHMetrics > numGlyphs {
+		return nil, ErrInvalidFont
+	}
+	r := &reader{buf: data}
+	flags := r.u8()
+	advances := make([]uint16, numHMetrics)
+	for i := range advances {
+		advances[i] = r.u16()
+	}
+	lsbs := make([]int16, numGlyphs)
+	for i := 0; i < numHMetrics; i++ {
+		if flags&1 != 0 {
+			lsbs[i] = xMins[i]
+		} else {
+			lsbs[i] = int16(r.u16())
+		}
+	}
+	for i := numHMetrics; i < numGlyphs; i++ {
+		if flags&2 != 0 {
+			lsbs[i] = xMins[i]
+		} else {
+			lsbs[i] = int16(r.u16())
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	out := make([]byte, 0, 4*numHMetrics+2*(numGlyphs-numHMetrics))
+	for i := 0; i < numGlyphs; i++ {
+		if i < numHMetrics {
+			out = binary.BigEndian.AppendUint16(out, advances[i])
+		}
+		out = binary.BigEndian.AppendUint16(out, uint16(lsbs[i]))
+	}
+	return out, nil
+}
+
+// knownTags are the woff2 known table tags.
+var knownTags = [64]string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post",
+	"cvt ", "fpgm", "glyf", "loca", "prep", "CFF ", "VORG", "EBDT",
+	"EBLC", "gasp", "hdmx", "kern", "LTSH", "PCLT", "VDMX", "vhea",
+	"vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC", "JSTF", "MATH",
+	"CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar",
+	"gvar", "hsty", "just", "lcar", "mort", "morx", "opbd", "prop",
+	"trak", "Zapf", "Silf", "Glat", "Gloc", "Feat", "Sill", "",
+}
+
+// reader is a big endian byte reader. Reading past the end of the buffer
+// sets err.
+type reader struct {
+	buf []byte
+	off int
+	err error
+}
+
+// bytes reads n bytes. Reading past the end of the buffer returns a small
+// zeroed buffer, sufficient for the fixed size reads (u8, u16, u32), and
+// not sized by n, as n may be read from untrusted input.
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || len(r.buf)-r.off < n {
+		r.err = ErrInvalidFont
+		return make([]byte, 4)
+	}
+	b := r.buf[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+// skip skips n bytes.
+func (r *reader) skip(n int) {
+	r.bytes(n)
+}
+
+// u8 reads a uint8.
+func (r *reader) u8() uint8 {
+	return r.bytes(1)[0]
+}
+
+// u16 reads a uint16.
+func (r *reader) u16() uint16 {
+	return binary.BigEndian.Uint16(r.bytes(2))
+}
+
+// u32 reads a uint32.
+func (r *reader) u32() uint32 {
+	return binary.BigEndian.Uint32(r.bytes(4))
+}
+
+// base128 reads a UIntBase128.
+func (r *reader) base128() uint32 {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b := r.u8()
+		if (i == 0 && b == 0x80) || v&0xfe000000 != 0 {
+			r.err = ErrInvalidFont
+			return 0
+		}
+		v = v<<7 | uint32(b&0x7f)
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+	r.err = ErrInvalidFont
+	return 0
+}
+
+// u255 reads a 255UInt16.
+func (r *reader) u255() uint16 {
+	switch code := r.u8(); code {
+	case 253:
+		return r.u16()
+	case 255:
+		return uint16(r.u8()) + 253
+	case 254:
+		return uint16(r.u8()) + 506
+	default:
+		return uint16(code)
+	}
+}
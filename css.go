@@ -0,0 +1,311 @@
+package webfonts
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// cssRule is a parsed stylesheet rule.
+type cssRule struct {
+	// prelude is the rule's selector or at-rule prelude (ie, "@font-face",
+	// ".font-effect-fire").
+	prelude string
+	// block is the raw contents of the rule's block.
+	block string
+	// statement is whether or not the rule is a statement at-rule without a
+	// block (ie, "@import url(...);").
+	statement bool
+	// comment is the comment immediately preceding the rule.
+	comment string
+}
+
+// cssDecl is a parsed declaration.
+type cssDecl struct {
+	prop  string
+	value string
+}
+
+// cssScanner incrementally scans the rules of a stylesheet. Rules nested in
+// conditional group rules (ie, @media, @supports) are scanned as if they were
+// top-level rules.
+type cssScanner struct {
+	r       *bufio.Reader
+	comment string
+	// nested is the scanner for the conditional group rule being scanned.
+	nested *cssScanner
+}
+
+// newCSSScanner creates a stylesheet scanner for the reader.
+func newCSSScanner(r io.Reader) *cssScanner {
+	return &cssScanner{
+		r: bufio.NewReader(r),
+	}
+}
+
+// next returns the next rule in the stylesheet, or io.EOF when there are no
+// further rules.
+//
+// Comments between rules are not returned, but the last comment immediately
+// preceding a rule is associated with the rule.
+func (s *cssScanner) next() (*cssRule, error) {
+	// nested rules
+	if s.nested != nil {
+		rule, err := s.nested.next()
+		if err != io.EOF {
+			return rule, err
+		}
+		s.nested = nil
+	}
+	var prelude strings.Builder
+	for {
+		r, _, err := s.r.ReadRune()
+		switch {
+		case err == io.EOF && strings.TrimSpace(prelude.String()) != "":
+			return nil, io.ErrUnexpectedEOF
+		case err != nil:
+			return nil, err
+		}
+		switch r {
+		case '/':
+			ok, err := s.peek('*')
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				prelude.WriteRune(r)
+				continue
+			}
+			comment, err := s.readComment()
+			if err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(prelude.String()) == "" {
+				s.comment = comment
+			}
+		case '"', '\'':
+			prelude.WriteRune(r)
+			if err := s.readString(&prelude, r); err != nil {
+				return nil, err
+			}
+		case ';':
+			rule := &cssRule{
+				prelude:   strings.TrimSpace(prelude.String()),
+				statement: true,
+				comment:   s.comment,
+			}
+			s.comment = ""
+			if rule.prelude == "" {
+				continue
+			}
+			return rule, nil
+		case '{':
+			group := isGroupRule(prelude.String())
+			block, err := s.readBlock(group)
+			if err != nil {
+				return nil, err
+			}
+			if group {
+				s.comment, s.nested = "", newCSSScanner(strings.NewReader(block))
+				return s.next()
+			}
+			rule := &cssRule{
+				prelude: strings.TrimSpace(prelude.String()),
+				block:   block,
+				comment: s.comment,
+			}
+			s.comment = ""
+			return rule, nil
+		default:
+			prelude.WriteRune(r)
+		}
+	}
+}
+
+// groupRules are the conditional group at-rules containing nested rules.
+var groupRules = map[string]bool{
+	"@media":          true,
+	"@supports":       true,
+	"@document":       true,
+	"@-moz-document":  true,
+	"@layer":          true,
+	"@container":      true,
+	"@scope":          true,
+	"@starting-style": true,
+}
+
+// isGroupRule returns true when the prelude is a conditional group at-rule's
+// prelude (ie, "@media screen", "@supports (font-tech(variations))").
+func isGroupRule(prelude string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(prelude), " ")
+	if i := strings.IndexAny(name, "(\t\n\r"); i != -1 {
+		name = name[:i]
+	}
+	return groupRules[strings.ToLower(name)]
+}
+
+// peek consumes the next rune if it is r.
+func (s *cssScanner) peek(r rune) (bool, error) {
+	c, _, err := s.r.ReadRune()
+	switch {
+	case err == io.EOF:
+		return false, nil
+	case err != nil:
+		return false, err
+	case c != r:
+		return false, s.r.UnreadRune()
+	}
+	return true, nil
+}
+
+// readComment reads a comment, after the opening "/*", returning the trimmed
+// comment text.
+func (s *cssScanner) readComment() (string, error) {
+	var sb strings.Builder
+	for {
+		r, _, err := s.r.ReadRune()
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		} else if err != nil {
+			return "", err
+		}
+		if r == '*' {
+			ok, err := s.peek('/')
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				return strings.TrimSpace(sb.String()), nil
+			}
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// readString reads a string, after the opening quote, writing it (including
+// the closing quote) to sb.
+func (s *cssScanner) readString(sb *strings.Builder, quote rune) error {
+	for {
+		r, _, err := s.r.ReadRune()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+		sb.WriteRune(r)
+		switch r {
+		case '\\':
+			r, _, err := s.r.ReadRune()
+			if err != nil {
+				return io.ErrUnexpectedEOF
+			}
+			sb.WriteRune(r)
+		case quote:
+			return nil
+		}
+	}
+}
+
+// readBlock reads a block, after the opening "{", returning the contents of
+// the block (excluding the closing "}"). Comments are removed from the block,
+// unless keepComments is true.
+func (s *cssScanner) readBlock(keepComments bool) (string, error) {
+	var sb strings.Builder
+	depth := 1
+	for {
+		r, _, err := s.r.ReadRune()
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		} else if err != nil {
+			return "", err
+		}
+		switch r {
+		case '/':
+			ok, err := s.peek('*')
+			if err != nil {
+				return "", err
+			}
+			if ok {
+				comment, err := s.readComment()
+				if err != nil {
+					return "", err
+				}
+				if keepComments {
+					sb.WriteString("/* " + comment + " */")
+				}
+				continue
+			}
+		case '"', '\'':
+			sb.WriteRune(r)
+			if err := s.readString(&sb, r); err != nil {
+				return "", err
+			}
+			continue
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return sb.String(), nil
+			}
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// parseDecls parses the declarations in a block.
+func parseDecls(block string) []cssDecl {
+	var decls []cssDecl
+	for _, s := range splitOn(block, ';') {
+		i := strings.IndexByte(s, ':')
+		if i == -1 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(s[:i]))
+		value := strings.Join(strings.Fields(s[i+1:]), " ")
+		if prop == "" {
+			continue
+		}
+		decls = append(decls, cssDecl{
+			prop:  prop,
+			value: value,
+		})
+	}
+	return decls
+}
+
+// splitList splits a comma separated css value, ignoring commas contained in
+// quotes or parentheses.
+func splitList(s string) []string {
+	return splitOn(s, ',')
+}
+
+// splitOn splits s on sep, ignoring any sep contained in quotes, parentheses,
+// or braces. Empty values are removed.
+func splitOn(s string, sep rune) []string {
+	var v []string
+	var quote rune
+	depth, start := 0, 0
+	add := func(end int) {
+		if z := strings.TrimSpace(s[start:end]); z != "" {
+			v = append(v, z)
+		}
+	}
+	for i, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(' || r == '{':
+			depth++
+		case r == ')' || r == '}':
+			depth--
+		case r == sep && depth == 0:
+			add(i)
+			start = i + 1
+		}
+	}
+	add(len(s))
+	return v
+}
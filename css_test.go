@@ -0,0 +1,164 @@
+package webfonts
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSSScanner(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		exp  []cssRule
+	}{
+		{"empty", "", nil},
+		{"comment only", "/* latin */", nil},
+		{
+			"font face",
+			"/* latin */\n@font-face {\n  font-family: 'A';\n}\n",
+			[]cssRule{
+				{prelude: "@font-face", block: "\n  font-family: 'A';\n", comment: "latin"},
+			},
+		},
+		{
+			"last comment",
+			"/* a */ /* b */ @font-face { src: url(a.woff2); }",
+			[]cssRule{
+				{prelude: "@font-face", block: " src: url(a.woff2); ", comment: "b"},
+			},
+		},
+		{
+			"comment not carried",
+			"/* a */ @font-face { } @font-face { }",
+			[]cssRule{
+				{prelude: "@font-face", block: " ", comment: "a"},
+				{prelude: "@font-face", block: " "},
+			},
+		},
+		{
+			"block comments removed",
+			"@font-face { /* x */font-family: 'A'; }",
+			[]cssRule{
+				{prelude: "@font-face", block: " font-family: 'A'; "},
+			},
+		},
+		{
+			"statement",
+			"@charset \"utf-8\"; @import url('a;b.css');",
+			[]cssRule{
+				{prelude: `@charset "utf-8"`, statement: true},
+				{prelude: "@import url('a;b.css')", statement: true},
+			},
+		},
+		{
+			"strings",
+			`@font-face { font-family: "A } {"; src: local('B\'s'); }`,
+			[]cssRule{
+				{prelude: "@font-face", block: ` font-family: "A } {"; src: local('B\'s'); `},
+			},
+		},
+		{
+			"effect",
+			".font-effect-fire { color: red; }",
+			[]cssRule{
+				{prelude: ".font-effect-fire", block: " color: red; "},
+			},
+		},
+		{
+			"nested",
+			"@media screen { /* latin */ @font-face { font-weight: 400; } } @supports (font-tech(variations)) { @font-face { font-weight: 700; } } .a { }",
+			[]cssRule{
+				{prelude: "@font-face", block: " font-weight: 400; ", comment: "latin"},
+				{prelude: "@font-face", block: " font-weight: 700; "},
+				{prelude: ".a", block: " "},
+			},
+		},
+		{
+			"deeply nested",
+			"@layer base { @media (min-width: 1px) { @font-face { font-weight: 400; } } }",
+			[]cssRule{
+				{prelude: "@font-face", block: " font-weight: 400; "},
+			},
+		},
+		{
+			"nested block",
+			"@keyframes x { from { color: red; } }",
+			[]cssRule{
+				{prelude: "@keyframes x", block: " from { color: red; } "},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := scanAll(test.s)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(rules, test.exp) {
+				t.Errorf("expected:\n%#v\ngot:\n%#v", test.exp, rules)
+			}
+		})
+	}
+}
+
+func TestCSSScannerUnexpectedEOF(t *testing.T) {
+	tests := []string{
+		"@font-face",
+		"@font-face {",
+		"@font-face { font-family: 'A",
+		"/* latin",
+		"@media screen { @font-face { }",
+		`@import "a`,
+	}
+	for _, s := range tests {
+		if _, err := scanAll(s); err != io.ErrUnexpectedEOF {
+			t.Errorf("%q: expected %v, got: %v", s, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+func TestParseDecls(t *testing.T) {
+	decls := parseDecls(" Font-Family: 'A;B' ; src: url(a.woff2) format('woff2'),\n  url(a.woff) format('woff'); ; invalid; :x; ")
+	exp := []cssDecl{
+		{prop: "font-family", value: "'A;B'"},
+		{prop: "src", value: "url(a.woff2) format('woff2'), url(a.woff) format('woff')"},
+	}
+	if !reflect.DeepEqual(decls, exp) {
+		t.Errorf("expected %#v, got: %#v", exp, decls)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp []string
+	}{
+		{"", nil},
+		{"a, b ,c", []string{"a", "b", "c"}},
+		{"local('a, b'), url(\"c,d\") format('woff2')", []string{"local('a, b')", "url(\"c,d\") format('woff2')"}},
+		{"url(a) tech(color-COLRv1, variations), , local(b)", []string{"url(a) tech(color-COLRv1, variations)", "local(b)"}},
+	}
+	for _, test := range tests {
+		if v := splitList(test.s); !reflect.DeepEqual(v, test.exp) {
+			t.Errorf("%q: expected %q, got: %q", test.s, test.exp, v)
+		}
+	}
+}
+
+// scanAll scans all rules in the stylesheet.
+func scanAll(s string) ([]cssRule, error) {
+	sc := newCSSScanner(strings.NewReader(s))
+	var rules []cssRule
+	for {
+		rule, err := sc.next()
+		switch {
+		case err == io.EOF:
+			return rules, nil
+		case err != nil:
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+}
@@ -0,0 +1,207 @@
+package webfonts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/verhist"
+	"github.com/kenshaw/diskcache"
+)
+
+// Doctor runs diagnostic checks using the client options, returning a report.
+func Doctor(ctx context.Context, opts ...ClientOption) *Report {
+	return NewClient(opts...).Doctor(ctx)
+}
+
+// Report is a diagnostic report.
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// OK returns true when none of the report's checks failed.
+func (r *Report) OK() bool {
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			return false
+		}
+	}
+	return true
+}
+
+// String satisfies the fmt.Stringer interface.
+func (r *Report) String() string {
+	var s string
+	for _, c := range r.Checks {
+		s += fmt.Sprintf("%-4s %s: %s (%v)\n", c.Status, c.Name, c.Message, c.Duration.Round(time.Millisecond))
+	}
+	return s
+}
+
+// add adds a check to the report.
+func (r *Report) add(name string, start time.Time, status CheckStatus, format string, v ...interface{}) {
+	r.Checks = append(r.Checks, Check{
+		Name:     name,
+		Status:   status,
+		Message:  fmt.Sprintf(format, v...),
+		Duration: time.Since(start),
+	})
+}
+
+// Check is a diagnostic check result.
+type Check struct {
+	Name     string        `json:"name"`
+	Status   CheckStatus   `json:"status"`
+	Message  string        `json:"message,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// CheckStatus is a diagnostic check status.
+type CheckStatus string
+
+// Check statuses.
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// MaxClockSkew is the maximum clock skew tolerated by Doctor.
+var MaxClockSkew = 1 * time.Minute
+
+// Doctor runs diagnostic checks for the client's cache directory, user agent
+// resolution, endpoint reachability, clock skew, and api key validity,
+// returning a report.
+func (cl *Client) Doctor(ctx context.Context) *Report {
+	r := new(Report)
+	// use the uncached transport when the client has been initialized
+	transport := cl.transport
+	if cl.base != nil {
+		transport = cl.base
+	}
+	cl.checkCacheDir(r)
+	cl.checkUserAgent(ctx, r, transport)
+	cl.checkEndpoints(ctx, r, transport)
+	cl.checkKey(ctx, r)
+	return r
+}
+
+// checkCacheDir checks that the client's cache directory is writable.
+func (cl *Client) checkCacheDir(r *Report) {
+	start := time.Now()
+	if cl.appCacheDir == "" {
+		r.add("cache", start, CheckSkip, "no app cache dir configured")
+		return
+	}
+	dir, err := diskcache.UserCacheDir(cl.appCacheDir)
+	if err != nil {
+		r.add("cache", start, CheckFail, "unable to determine cache dir: %v", err)
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		r.add("cache", start, CheckFail, "unable to create %s: %v", dir, err)
+		return
+	}
+	f, err := os.CreateTemp(dir, ".doctor-")
+	if err != nil {
+		r.add("cache", start, CheckFail, "%s is not writable: %v", dir, err)
+		return
+	}
+	name := f.Name()
+	_ = f.Close()
+	if err := os.Remove(name); err != nil {
+		r.add("cache", start, CheckWarn, "unable to remove %s: %v", filepath.Base(name), err)
+		return
+	}
+	r.add("cache", start, CheckOK, "%s is writable", dir)
+}
+
+// checkUserAgent checks that the user agent can be resolved.
+func (cl *Client) checkUserAgent(ctx context.Context, r *Report, transport http.RoundTripper) {
+	start := time.Now()
+	if cl.userAgent != "" {
+		r.add("user-agent", start, CheckOK, "%s", cl.userAgent)
+		return
+	}
+	userAgent, err := verhist.UserAgent(ctx, "linux", "stable", verhist.WithTransport(transport))
+	if err != nil {
+		r.add("user-agent", start, CheckWarn, "unable to resolve user agent, using fallback: %v", err)
+		return
+	}
+	r.add("user-agent", start, CheckOK, "%s", userAgent)
+}
+
+// checkEndpoints checks that the client's provider endpoints are reachable,
+// and that the local clock is not skewed relative to the endpoint.
+func (cl *Client) checkEndpoints(ctx context.Context, r *Report, transport http.RoundTripper) {
+	hc := &http.Client{
+		Transport: transport,
+	}
+	var date time.Time
+	var recv time.Time
+	for _, urlstr := range cl.provider.Stylesheet(cl, NewQuery("Roboto")) {
+		start := time.Now()
+		name := "endpoint " + endpointName(urlstr)
+		req, err := http.NewRequest("GET", urlstr, nil)
+		if err != nil {
+			r.add(name, start, CheckFail, "%v", err)
+			continue
+		}
+		req.Header.Set("User-Agent", UserAgentWOFF2)
+		res, err := hc.Do(req.WithContext(ctx))
+		if err != nil {
+			r.add(name, start, CheckFail, "unreachable: %v", err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			r.add(name, start, CheckFail, "status %d", res.StatusCode)
+			continue
+		}
+		if t, err := http.ParseTime(res.Header.Get("Date")); err == nil && date.IsZero() {
+			date, recv = t, time.Now()
+		}
+		r.add(name, start, CheckOK, "reachable")
+	}
+	// clock skew
+	start := time.Now()
+	if date.IsZero() {
+		r.add("clock", start, CheckSkip, "no server date available")
+		return
+	}
+	skew := recv.Sub(date).Round(time.Second)
+	if skew > MaxClockSkew || skew < -MaxClockSkew {
+		r.add("clock", start, CheckWarn, "local clock skewed by %v", skew)
+		return
+	}
+	r.add("clock", start, CheckOK, "skew %v", skew)
+}
+
+// endpointName returns the scheme and host of the url.
+func endpointName(urlstr string) string {
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return urlstr
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// checkKey checks that the configured api key or token source is valid.
+func (cl *Client) checkKey(ctx context.Context, r *Report) {
+	start := time.Now()
+	if cl.key == "" && cl.source == nil {
+		r.add("api key", start, CheckSkip, "no api key or token source configured")
+		return
+	}
+	families, err := cl.Available(ctx)
+	if err != nil {
+		r.add("api key", start, CheckFail, "%v", err)
+		return
+	}
+	r.add("api key", start, CheckOK, "%d families available", len(families))
+}
@@ -0,0 +1,206 @@
+package webfonts
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileInfo describes a downloaded font file.
+type FileInfo struct {
+	Font        Font   `json:"font"`
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType,omitempty"`
+	Integrity   string `json:"integrity,omitempty"`
+}
+
+// Download retrieves the font faces for the specified family, downloading
+// each font face's src to a file in dir, returning the written files.
+//
+// Files are named family-style-weight[-subset].format (see FileName).
+func (cl *Client) Download(ctx context.Context, family, dir string, opts ...QueryOption) ([]FileInfo, error) {
+	fonts, err := cl.Faces(ctx, family, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cl.DownloadFonts(ctx, fonts, dir)
+}
+
+// FontFile retrieves the font file for the font face's src. Failures are
+// returned as a *FamilyError.
+func (cl *Client) FontFile(ctx context.Context, font Font) ([]byte, error) {
+	// initialize
+	if err := cl.init(ctx); err != nil {
+		return nil, err
+	}
+	if cl.cl == nil {
+		return nil, ErrClientUninitialized
+	}
+	buf, err := cl.download(ctx, font.Src)
+	if err != nil {
+		return nil, &FamilyError{Family: font.Family, Format: font.Format, URL: font.Src, Err: err}
+	}
+	return buf, nil
+}
+
+// DownloadFonts downloads each font face's src to a file in dir, returning the
+// written files. When verification is enabled (see WithVerify), each
+// downloaded font file is verified before being written. When integrity
+// hashes are enabled (see WithIntegrity), the subresource integrity manifest
+// is written to dir.
+//
+// Failed downloads are returned as a MultiError of *FamilyError. When
+// continuing on error (see WithContinueOnError), the files successfully
+// written are returned along with the error.
+func (cl *Client) DownloadFonts(ctx context.Context, fonts []Font, dir string) ([]FileInfo, error) {
+	// initialize
+	if err := cl.init(ctx); err != nil {
+		return nil, err
+	}
+	if cl.cl == nil {
+		return nil, ErrClientUninitialized
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	// download
+	prog := cl.newProgress(len(fonts))
+	files := make([]FileInfo, len(fonts))
+	err := parallel(cl.concurrency, len(fonts), func(i int) error {
+		buf, err := cl.download(prog.with(ctx, fonts[i].Family, fonts[i].Format), fonts[i].Src)
+		if err == nil && cl.verify {
+			err = VerifyFont(fonts[i], buf)
+		}
+		name := filepath.Join(dir, FileName(fonts[i]))
+		if err == nil {
+			err = ioutil.WriteFile(name, buf, 0o644)
+		}
+		if err != nil {
+			return &FamilyError{Family: fonts[i].Family, Format: fonts[i].Format, URL: fonts[i].Src, Err: err}
+		}
+		files[i] = FileInfo{
+			Font:        fonts[i],
+			Path:        name,
+			Size:        int64(len(buf)),
+			ContentType: fonts[i].Format.ContentType(),
+		}
+		if cl.integrity {
+			files[i].Integrity = Integrity(buf)
+		}
+		return nil
+	})
+	if err != nil {
+		if !cl.continueOnError {
+			return nil, err
+		}
+		// remove failed
+		var v []FileInfo
+		for _, file := range files {
+			if file.Path != "" {
+				v = append(v, file)
+			}
+		}
+		files = v
+	}
+	// write manifest
+	if cl.integrity {
+		routes := make([]Route, len(files))
+		for i, file := range files {
+			routes[i] = Route{
+				Path:      filepath.Base(file.Path),
+				URL:       file.Font.Src,
+				Integrity: file.Integrity,
+			}
+		}
+		buf, err := buildIntegrityManifest(routes)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, IntegrityManifest), buf, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return files, err
+}
+
+// download retrieves the url, returning the response body. Concurrent
+// downloads of the same url are deduplicated (see share).
+func (cl *Client) download(ctx context.Context, urlstr string) (_ []byte, err error) {
+	urlpath, _, _ := strings.Cut(urlstr, "?")
+	ctx, span := cl.startSpan(ctx, "download",
+		attrURL.String(urlstr),
+		attrFormat.String(string(formatName(path.Ext(urlpath)))),
+	)
+	defer endSpan(span, &err)
+	v, err := share(ctx, &cl.group, "download\x00"+urlstr, cl.sharedTimeout(), func(ctx context.Context) (interface{}, error) {
+		return cl.doDownload(ctx, urlstr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// doDownload retrieves the url, returning the response body.
+func (cl *Client) doDownload(ctx context.Context, urlstr string) ([]byte, error) {
+	req, err := http.NewRequest("GET", urlstr, nil)
+	if err != nil {
+		return nil, err
+	}
+	// execute
+	p := &Provenance{
+		URL:    urlstr,
+		Cached: true,
+	}
+	res, err := cl.cl.Do(req.WithContext(withProvenance(ctx, p)))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	// check status
+	if res.StatusCode != http.StatusOK {
+		return nil, newStatusError(urlstr, res)
+	}
+	buf, err := ioutil.ReadAll(progressBody(ctx, urlstr, res.Body, res.ContentLength))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", urlstr, err)
+	}
+	cl.collect(KindFont, p, len(buf))
+	return buf, nil
+}
+
+// FileName returns a stable file name for the font, in the form of
+// family-style-weight[-subset].format. Font faces without a subset but with a
+// unicode-range (ie, the numbered slices served for large families) use the
+// first 7 characters of the md5 hash of the unicode-range in place of the
+// subset.
+func FileName(font Font) string {
+	v := []string{font.Family, font.Style, font.Weight}
+	if subset := subsetName(font); subset != "" {
+		v = append(v, subset)
+	}
+	for i := range v {
+		v[i] = strings.Trim(fileNameRE.ReplaceAllString(strings.ToLower(v[i]), "-"), "-")
+	}
+	return strings.Join(v, "-") + font.Format.Extension()
+}
+
+// subsetName returns the font's subset, or the short md5 hash of the font's
+// unicode-range when the font does not have a subset.
+func subsetName(font Font) string {
+	if font.Subset != "" || len(font.Range) == 0 {
+		return font.Subset
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(strings.Join(font.Range, ", "))))[:7]
+}
+
+// fileNameRE matches characters not allowed in file names.
+var fileNameRE = regexp.MustCompile(`[^a-z0-9]+`)
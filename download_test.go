@@ -0,0 +1,29 @@
+package webfonts
+
+import (
+	"testing"
+)
+
+func TestFileName(t *testing.T) {
+	font := Font{Family: "Noto Sans JP", Style: "normal", Weight: "400", Format: FormatWOFF2}
+	if s, exp := FileName(font), "noto-sans-jp-normal-400.woff2"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	font.Subset = "latin"
+	if s, exp := FileName(font), "noto-sans-jp-normal-400-latin.woff2"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	// numbered slices
+	font.Subset = ""
+	a, b := font, font
+	a.Range, b.Range = []string{"U+25ee8", "U+25f23"}, []string{"U+1f235-1f23b"}
+	switch x, y := FileName(a), FileName(b); {
+	case x == y:
+		t.Errorf("expected distinct file names, got: %q", x)
+	case x == FileName(font), y == FileName(font):
+		t.Errorf("expected unicode-range in file names, got: %q %q", x, y)
+	}
+	if x, y := VerboseName(a), VerboseName(b); x == y {
+		t.Errorf("expected distinct verbose names, got: %q", x)
+	}
+}
@@ -0,0 +1,104 @@
+package webfonts
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Font encodings.
+const (
+	EncodingJSON   = "json"
+	EncodingNDJSON = "ndjson"
+	EncodingYAML   = "yaml"
+)
+
+// EncodeFonts encodes the font faces to w using the encoding (json, ndjson,
+// or yaml), allowing font faces to be persisted and later reloaded (see
+// DecodeFonts). ndjson writes one font face per line.
+func EncodeFonts(w io.Writer, fonts []Font, encoding string) error {
+	if fonts == nil {
+		fonts = make([]Font, 0)
+	}
+	switch strings.ToLower(encoding) {
+	case EncodingJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(fonts)
+	case EncodingNDJSON:
+		enc := json.NewEncoder(w)
+		for _, font := range fonts {
+			if err := enc.Encode(font); err != nil {
+				return err
+			}
+		}
+		return nil
+	case EncodingYAML:
+		// round trip through json to use the json field names
+		buf, err := json.Marshal(fonts)
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		if err := json.Unmarshal(buf, &v); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("%q: %w", encoding, ErrInvalidEncoding)
+}
+
+// DecodeFonts decodes font faces from r using the encoding (json, ndjson, or
+// yaml). See EncodeFonts.
+func DecodeFonts(r io.Reader, encoding string) ([]Font, error) {
+	var fonts []Font
+	switch strings.ToLower(encoding) {
+	case EncodingJSON:
+		if err := json.NewDecoder(r).Decode(&fonts); err != nil {
+			return nil, err
+		}
+	case EncodingNDJSON:
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, 1<<24)
+		for s.Scan() {
+			line := bytes.TrimSpace(s.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var font Font
+			if err := json.Unmarshal(line, &font); err != nil {
+				return nil, err
+			}
+			fonts = append(fonts, font)
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+	case EncodingYAML:
+		// round trip through json to use the json field names
+		var v interface{}
+		if err := yaml.NewDecoder(r).Decode(&v); err != nil && err != io.EOF {
+			return nil, err
+		}
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(buf, &fonts); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%q: %w", encoding, ErrInvalidEncoding)
+	}
+	return fonts, nil
+}
@@ -0,0 +1,121 @@
+package webfonts
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+)
+
+// ExportOption is a catalog export option.
+type ExportOption func(*exportOptions)
+
+// exportOptions are catalog export options.
+type exportOptions struct {
+	preview func(Family) string
+}
+
+// WithExportPreview is a catalog export option to set the func returning the
+// preview stylesheet url for a family (default: PreviewURL). A func returning
+// an empty string omits the preview url.
+func WithExportPreview(preview func(Family) string) ExportOption {
+	return func(o *exportOptions) {
+		o.preview = preview
+	}
+}
+
+// PreviewURL returns a css2 api stylesheet url for the family retrieving only
+// the glyphs needed to render the family's name, suitable for previewing the
+// family in a font picker.
+func PreviewURL(family Family) string {
+	return string(MirrorGoogle) + "/css2?family=" + url.QueryEscape(family.Name) + "&text=" + url.QueryEscape(family.Name) + "&display=swap"
+}
+
+// ExportedCatalog is a compact catalog of font families for frontend font
+// pickers. See ExportCatalog.
+type ExportedCatalog struct {
+	// Categories are the distinct family categories.
+	Categories []string `json:"categories"`
+	// Subsets are the distinct family subsets.
+	Subsets []string `json:"subsets"`
+	// Families are the exported families.
+	Families []ExportedFamily `json:"families"`
+}
+
+// ExportedFamily is an exported family.
+type ExportedFamily struct {
+	// Name is the family name.
+	Name string `json:"n"`
+	// Category is the index of the family's category in the catalog's
+	// categories.
+	Category int `json:"c"`
+	// Variants is the bitmask of the family's variants. Bits 0-8 are the
+	// normal weights 100-900, and bits 9-17 are the italic weights 100-900
+	// (see VariantMask).
+	Variants int `json:"v"`
+	// Subsets are the indexes of the family's subsets in the catalog's
+	// subsets.
+	Subsets []int `json:"s,omitempty"`
+	// Variable is whether the family has variable font axes.
+	Variable bool `json:"x,omitempty"`
+	// Preview is the family's preview stylesheet url.
+	Preview string `json:"p,omitempty"`
+}
+
+// ExportCatalog writes a compact json catalog of the families to w (see
+// ExportedCatalog), optimized for frontend font pickers.
+func ExportCatalog(w io.Writer, families []Family, opts ...ExportOption) error {
+	o := &exportOptions{
+		preview: PreviewURL,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c := ExportedCatalog{
+		Categories: make([]string, 0),
+		Subsets:    make([]string, 0),
+		Families:   make([]ExportedFamily, len(families)),
+	}
+	categories, subsets := make(map[string]int), make(map[string]int)
+	index := func(v *[]string, m map[string]int, s string) int {
+		i, ok := m[s]
+		if !ok {
+			i, m[s] = len(*v), len(*v)
+			*v = append(*v, s)
+		}
+		return i
+	}
+	for i, family := range families {
+		c.Families[i] = ExportedFamily{
+			Name:     family.Name,
+			Category: index(&c.Categories, categories, family.Category),
+			Variants: VariantMask(family.Variants),
+			Variable: family.Variable(),
+		}
+		for _, subset := range family.Subsets {
+			c.Families[i].Subsets = append(c.Families[i].Subsets, index(&c.Subsets, subsets, subset))
+		}
+		if o.preview != nil {
+			c.Families[i].Preview = o.preview(family)
+		}
+	}
+	return json.NewEncoder(w).Encode(c)
+}
+
+// VariantMask returns the bitmask of the variants (ie, "regular", "700",
+// "700italic"). Bits 0-8 are the normal weights 100-900, and bits 9-17 are
+// the italic weights 100-900. Unrecognized variants are ignored.
+func VariantMask(variants []string) int {
+	mask := 0
+	for _, variant := range variants {
+		weight, style, err := NormalizeVariant(variant)
+		if err != nil || 900 < weight || weight%100 != 0 {
+			continue
+		}
+		bit := weight/100 - 1
+		if style == "italic" {
+			bit += 9
+		}
+		mask |= 1 << bit
+	}
+	return mask
+}
@@ -0,0 +1,169 @@
+package webfonts
+
+import (
+	"sort"
+	"strings"
+)
+
+// FaceGroup is a logical font face, the font faces for a family, style, and
+// weight combination across subsets and formats.
+type FaceGroup struct {
+	Family string `json:"font-family,omitempty"`
+	Style  string `json:"font-style,omitempty"`
+	Weight string `json:"font-weight,omitempty"`
+	// Subsets are the group's per-subset font faces, in the order first seen.
+	Subsets []FaceSubset `json:"subsets,omitempty"`
+}
+
+// FaceSubset is the font faces for a subset of a logical font face.
+type FaceSubset struct {
+	Subset string   `json:"subset,omitempty"`
+	Range  []string `json:"unicode-range,omitempty"`
+	// Fonts are the subset's font faces, one for each format.
+	Fonts []Font `json:"fonts,omitempty"`
+}
+
+// Format returns the subset's font face with the format.
+func (s FaceSubset) Format(format Format) (Font, bool) {
+	for _, font := range s.Fonts {
+		if font.Format == format {
+			return font, true
+		}
+	}
+	return Font{}, false
+}
+
+// Fonts returns the group's font faces.
+func (g FaceGroup) Fonts() []Font {
+	var fonts []Font
+	for _, s := range g.Subsets {
+		fonts = append(fonts, s.Fonts...)
+	}
+	return fonts
+}
+
+// GroupFonts groups the font faces by family, style, and weight. Groups are
+// ordered deterministically by family, numeric weight (see NormalizeWeight),
+// and then style (normal, italic, oblique by angle, then any other style).
+// Within each group, font faces are split by subset (or by unicode-range, for
+// font faces without a subset) in the order the subsets are first seen, with
+// font faces without a subset or unicode-range included in each subset. A
+// group with no subsets has a single subset with an empty name.
+func GroupFonts(fonts []Font) []FaceGroup {
+	// arrange by family, style, weight
+	type key struct {
+		family, style, weight string
+	}
+	var keys []key
+	m := make(map[key][]Font)
+	for _, font := range fonts {
+		k := key{font.Family, font.Style, font.Weight}
+		if _, ok := m[k]; !ok {
+			keys = append(keys, k)
+		}
+		m[k] = append(m[k], font)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		switch {
+		case keys[i].family != keys[j].family:
+			return keys[i].family < keys[j].family
+		case keys[i].weight != keys[j].weight:
+			return compareWeights(keys[i].weight, keys[j].weight) < 0
+		}
+		return compareStyles(keys[i].style, keys[j].style) < 0
+	})
+	groups := make([]FaceGroup, len(keys))
+	for i, k := range keys {
+		groups[i] = FaceGroup{
+			Family:  k.family,
+			Style:   k.style,
+			Weight:  k.weight,
+			Subsets: groupSubsets(m[k]),
+		}
+	}
+	return groups
+}
+
+// groupSubsets splits the font faces by subset, with font faces without a
+// subset split by unicode-range (ie, the numbered slices served for large
+// families), and font faces without a subset or unicode-range included in
+// each subset.
+func groupSubsets(fonts []Font) []FaceSubset {
+	type key struct {
+		subset, unicodeRange string
+	}
+	var keys []key
+	var common []Font
+	bySubset := make(map[key][]Font)
+	for _, font := range fonts {
+		var k key
+		switch {
+		case font.Subset != "":
+			k.subset = font.Subset
+		case len(font.Range) != 0:
+			k.unicodeRange = strings.Join(font.Range, ", ")
+		default:
+			common = append(common, font)
+			continue
+		}
+		if _, ok := bySubset[k]; !ok {
+			keys = append(keys, k)
+		}
+		bySubset[k] = append(bySubset[k], font)
+	}
+	if len(keys) == 0 {
+		return []FaceSubset{newFaceSubset("", common)}
+	}
+	v := make([]FaceSubset, len(keys))
+	for i, k := range keys {
+		v[i] = newFaceSubset(k.subset, append(bySubset[k], common...))
+	}
+	return v
+}
+
+// newFaceSubset creates a face subset, using the first unicode-range of the
+// font faces.
+func newFaceSubset(subset string, fonts []Font) FaceSubset {
+	s := FaceSubset{
+		Subset: subset,
+		Fonts:  fonts,
+	}
+	for _, font := range fonts {
+		if len(font.Range) != 0 {
+			s.Range = font.Range
+			break
+		}
+	}
+	return s
+}
+
+// styleOrder is the sort order of font styles.
+var styleOrder = map[string]int{
+	"normal":  0,
+	"italic":  1,
+	"oblique": 2,
+}
+
+// compareStyles compares the font styles, ordering normal, italic, and
+// oblique (by angle) before any other style, falling back to comparing the
+// styles as strings.
+func compareStyles(a, b string) int {
+	rank := func(style string) (StyleRange, int) {
+		sr, err := ParseStyleRange(style)
+		if err != nil {
+			return sr, len(styleOrder)
+		}
+		return sr, styleOrder[sr.Style]
+	}
+	x, i := rank(a)
+	y, j := rank(b)
+	switch {
+	case i != j:
+		return i - j
+	case x.Min < y.Min, x.Min == y.Min && x.Max < y.Max:
+		return -1
+	case x.Min > y.Min, x.Max > y.Max:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,112 @@
+package webfonts
+
+import (
+	"strings"
+	"time"
+
+	gfonts "google.golang.org/api/webfonts/v1"
+)
+
+// Family describes an available font family.
+type Family struct {
+	Name         string            `json:"family"`
+	Category     string            `json:"category,omitempty"`
+	Variants     []string          `json:"variants,omitempty"`
+	Subsets      []string          `json:"subsets,omitempty"`
+	Version      string            `json:"version,omitempty"`
+	LastModified time.Time         `json:"lastModified,omitempty"`
+	Files        map[string]string `json:"files,omitempty"`
+	Menu         string            `json:"menu,omitempty"`
+	Axes         []Axis            `json:"axes,omitempty"`
+	// PopularityRank is the family's 1-based popularity rank, set when the
+	// available families are sorted by popularity (see WithSort).
+	PopularityRank int `json:"popularityRank,omitempty"`
+	// TrendingRank is the family's 1-based trending rank, set when the
+	// available families are sorted by trending (see WithSort).
+	TrendingRank int `json:"trendingRank,omitempty"`
+}
+
+// Axis describes a variable font axis.
+type Axis struct {
+	Tag   string  `json:"tag"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// FamilyFromWebfont converts a google webfonts api webfont to a family.
+func FamilyFromWebfont(f *gfonts.Webfont) Family {
+	family := Family{
+		Name:     f.Family,
+		Category: f.Category,
+		Variants: f.Variants,
+		Subsets:  f.Subsets,
+		Version:  f.Version,
+		Files:    f.Files,
+		Menu:     f.Menu,
+	}
+	if t, err := time.Parse("2006-01-02", f.LastModified); err == nil {
+		family.LastModified = t
+	}
+	for _, axis := range f.Axes {
+		family.Axes = append(family.Axes, Axis{
+			Tag:   axis.Tag,
+			Start: axis.Start,
+			End:   axis.End,
+		})
+	}
+	return family
+}
+
+// Variable returns true when the family has variable font axes.
+func (f Family) Variable() bool {
+	return len(f.Axes) != 0
+}
+
+// Italic returns true when the family has italic variants.
+func (f Family) Italic() bool {
+	for _, variant := range f.Variants {
+		if strings.HasSuffix(variant, "italic") {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterOptions are family filter options. See FilterFamilies.
+type FilterOptions struct {
+	// Category is the category to filter by (ie, "sans-serif").
+	Category string `json:"category,omitempty"`
+	// Subset is the subset to filter by (ie, "latin-ext").
+	Subset string `json:"subset,omitempty"`
+	// SupportsItalic filters by families with italic variants.
+	SupportsItalic bool `json:"supportsItalic,omitempty"`
+	// VariableOnly filters by families with variable font axes. Axes are only
+	// included when the families are listed with CapabilityVF (see
+	// WithCapability).
+	VariableOnly bool `json:"variableOnly,omitempty"`
+}
+
+// Match returns true when the family matches the filter options.
+func (o FilterOptions) Match(f Family) bool {
+	switch {
+	case o.Category != "" && !strings.EqualFold(f.Category, o.Category),
+		o.Subset != "" && !contains(f.Subsets, o.Subset),
+		o.SupportsItalic && !f.Italic(),
+		o.VariableOnly && !f.Variable():
+		return false
+	}
+	return true
+}
+
+// FilterFamilies returns the families matching the filter options, in order.
+// Useful for narrowing the available families (see Available) for font
+// pickers and bundlers.
+func FilterFamilies(families []Family, o FilterOptions) []Family {
+	var v []Family
+	for _, f := range families {
+		if o.Match(f) {
+			v = append(v, f)
+		}
+	}
+	return v
+}
@@ -3,13 +3,10 @@ package webfonts
 import (
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/url"
 	"path"
 	"regexp"
 	"strings"
-
-	"github.com/vanng822/css"
 )
 
 // Font describes a font face.
@@ -21,87 +18,263 @@ type Font struct {
 	Display string   `json:"font-display,omitempty"`
 	Stretch string   `json:"font-stretch,omitempty"`
 	Src     string   `json:"src,omitempty"`
-	Format  string   `json:"format,omitempty"`
+	Format  Format   `json:"format,omitempty"`
 	Range   []string `json:"unicode-range,omitempty"`
+	// Sources are the src entries for the font. Src, Format, and Tech are the
+	// url, format, and tech() hints of the first url source.
+	Sources []Source `json:"sources,omitempty"`
+	// Tech are the tech() hints for the font (ie, "variations").
+	Tech []string `json:"tech,omitempty"`
+	// AscentOverride is the ascent-override descriptor.
+	AscentOverride string `json:"ascent-override,omitempty"`
+	// DescentOverride is the descent-override descriptor.
+	DescentOverride string `json:"descent-override,omitempty"`
+	// LineGapOverride is the line-gap-override descriptor.
+	LineGapOverride string `json:"line-gap-override,omitempty"`
+	// SizeAdjust is the size-adjust descriptor.
+	SizeAdjust string `json:"size-adjust,omitempty"`
+	// FeatureSettings is the font-feature-settings descriptor.
+	FeatureSettings string `json:"font-feature-settings,omitempty"`
+	// VariationSettings is the font-variation-settings descriptor.
+	VariationSettings string `json:"font-variation-settings,omitempty"`
+	// Extra are any unknown @font-face descriptors.
+	Extra map[string]string `json:"extra,omitempty"`
+	// Axes are the variable font axes requested for the font.
+	Axes map[string][]string `json:"axes,omitempty"`
+	// Provenance is where the stylesheet the font was parsed from was
+	// retrieved from.
+	Provenance *Provenance `json:"provenance,omitempty"`
 }
 
 // FontsFromStylesheetReader parses stylesheet from the passed reader,
 // returning any parsed font face.
+//
+// The stylesheet is parsed incrementally, rule by rule. A subset comment (ie,
+// "/* latin */") immediately preceding a @font-face rule sets the font's
+// subset. @font-face rules nested in conditional group rules (ie, @media,
+// @supports) are parsed as if they were top-level rules.
 func FontsFromStylesheetReader(r io.Reader) ([]Font, error) {
-	// load
-	buf, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	// subsets
-	s := string(buf)
-	subsets := subsetRE.FindAllStringSubmatch(s, -1)
-	// parse
-	rules := css.Parse(s).GetCSSRuleList()
+	s := newCSSScanner(r)
 	var fonts []Font
-	for i, rule := range rules {
-		if rule.Type != css.FONT_FACE_RULE {
+	for {
+		rule, err := s.next()
+		switch {
+		case err == io.EOF:
+			return fonts, nil
+		case err != nil:
+			return nil, err
+		case rule.statement || !strings.EqualFold(rule.prelude, "@font-face"):
 			continue
 		}
-		// build font
-		var font Font
-		if subsets != nil && i < len(subsets) {
-			font.Subset = subsets[i][1]
+		font, err := parseFontFace(rule)
+		if err != nil {
+			return nil, err
 		}
-		for _, style := range rule.Style.Styles {
-			switch style.Property {
-			case "font-family":
-				v := style.Value.Text()
-				font.Family = v[1 : len(v)-1]
-			case "font-style":
-				font.Style = style.Value.Text()
-			case "font-weight":
-				font.Weight = style.Value.Text()
-			case "font-display":
-				font.Display = style.Value.Text()
-			case "font-stretch":
-				font.Stretch = style.Value.Text()
-			case "src":
-				var err error
-				if font.Src, font.Format, err = parseSrcAndFormat(style.Value.Text()); err != nil {
-					return nil, err
-				}
-			case "unicode-range":
-				font.Range = strings.Split(style.Value.Text(), ",")
-				for i := 0; i < len(font.Range); i++ {
-					font.Range[i] = strings.TrimSpace(font.Range[i])
+		fonts = append(fonts, font)
+	}
+}
+
+// DedupeFonts returns the font faces with duplicates removed, keeping the
+// first font face for each family, style, weight, subset, and src.
+func DedupeFonts(fonts []Font) []Font {
+	type key struct {
+		family, style, weight, subset, src string
+	}
+	seen := make(map[key]bool)
+	var v []Font
+	for _, font := range fonts {
+		k := key{font.Family, font.Style, font.Weight, font.Subset, font.Src}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		v = append(v, font)
+	}
+	return v
+}
+
+// parseFontFace parses a @font-face rule.
+func parseFontFace(rule *cssRule) (Font, error) {
+	var font Font
+	if m := subsetRE.FindStringSubmatch(rule.comment); m != nil {
+		font.Subset = m[1]
+	}
+	for _, decl := range parseDecls(rule.block) {
+		switch decl.prop {
+		case "font-family":
+			font.Family = unquote(decl.value)
+		case "font-style":
+			font.Style = decl.value
+			// normalize oblique angle ranges (see Font.StyleRange)
+			if sr, err := ParseStyleRange(decl.value); err == nil && sr.Style == "oblique" && strings.Contains(decl.value, " ") {
+				font.Style = sr.String()
+			}
+		case "font-weight":
+			font.Weight = decl.value
+		case "font-display":
+			font.Display = decl.value
+		case "font-stretch":
+			font.Stretch = decl.value
+		case "src":
+			var err error
+			if font.Sources, err = parseSources(decl.value); err != nil {
+				return Font{}, err
+			}
+			for _, source := range font.Sources {
+				if source.URL != "" {
+					font.Src, font.Format, font.Tech = source.URL, source.Format, source.Tech
+					break
 				}
-			default:
-				panic(fmt.Sprintf("unknown @font-face property %q", style.Property))
-				return nil, fmt.Errorf("unknown @font-face property %q", style.Property)
 			}
+		case "unicode-range":
+			font.Range = splitList(decl.value)
+		case "ascent-override":
+			font.AscentOverride = decl.value
+		case "descent-override":
+			font.DescentOverride = decl.value
+		case "line-gap-override":
+			font.LineGapOverride = decl.value
+		case "size-adjust":
+			font.SizeAdjust = decl.value
+		case "font-feature-settings":
+			font.FeatureSettings = decl.value
+		case "font-variation-settings":
+			font.VariationSettings = decl.value
+		default:
+			if font.Extra == nil {
+				font.Extra = make(map[string]string)
+			}
+			font.Extra[decl.prop] = decl.value
 		}
-		fonts = append(fonts, font)
 	}
-	return fonts, nil
+	return font, nil
 }
 
-// subsetRE matches subset descriptions in the stylesheet.
-var subsetRE = regexp.MustCompile(`(?m)^/\*\s+([a-z0-9-]+)\s+\*/$`)
+// Effect describes a font effect rule.
+type Effect struct {
+	Name string `json:"name,omitempty"`
+	CSS  string `json:"css,omitempty"`
+}
 
-// parseSrcAndFormat parses the url and format in a stylesheet src property.
-func parseSrcAndFormat(src string) (string, string, error) {
-	// extract and parse url
-	m := srcRE.FindAllStringSubmatch(src, -1)
-	if len(m) != 1 {
-		return "", "", fmt.Errorf("invalid src %q", src)
+// EffectsFromStylesheetReader parses the stylesheet from the passed reader,
+// returning any parsed font effect rules.
+func EffectsFromStylesheetReader(r io.Reader) ([]Effect, error) {
+	s := newCSSScanner(r)
+	var effects []Effect
+	for {
+		rule, err := s.next()
+		switch {
+		case err == io.EOF:
+			return effects, nil
+		case err != nil:
+			return nil, err
+		case rule.statement:
+			continue
+		}
+		if effect, ok := parseEffect(rule); ok {
+			effects = append(effects, effect)
+		}
 	}
-	u, err := url.Parse(m[0][1])
-	if err != nil {
-		return "", "", fmt.Errorf("invalid src url %q", m[0][1])
+}
+
+// parseEffect parses a font effect rule, returning false when the rule is not
+// a font effect rule.
+func parseEffect(rule *cssRule) (Effect, bool) {
+	m := effectRE.FindStringSubmatch(rule.prelude)
+	if m == nil {
+		return Effect{}, false
+	}
+	// build css
+	var decls []string
+	for _, decl := range parseDecls(rule.block) {
+		decls = append(decls, "  "+decl.prop+": "+decl.value+";\n")
+	}
+	return Effect{
+		Name: m[1],
+		CSS:  rule.prelude + " {\n" + strings.Join(decls, "") + "}",
+	}, true
+}
+
+// effectRE matches effect class selectors.
+var effectRE = regexp.MustCompile(`\.font-effect-([a-z0-9-]+)`)
+
+// subsetRE matches subset comments.
+var subsetRE = regexp.MustCompile(`^([a-z0-9-]+)$`)
+
+// Source is a font face src entry.
+type Source struct {
+	URL    string   `json:"url,omitempty"`
+	Format Format   `json:"format,omitempty"`
+	Tech   []string `json:"tech,omitempty"`
+	Local  string   `json:"local,omitempty"`
+}
+
+// parseSources parses the urls, formats, tech() hints, and local names in a
+// stylesheet src property. Format hints may be quoted or unquoted keywords,
+// and the legacy "-variations" format hint suffix (ie,
+// format('woff2-variations')) is parsed as the "variations" tech() hint.
+func parseSources(src string) ([]Source, error) {
+	var sources []Source
+	for _, s := range splitList(src) {
+		m := srcRE.FindStringSubmatch(s)
+		switch {
+		case m == nil:
+			return nil, fmt.Errorf("%q: %w", s, ErrInvalidSrc)
+		case m[1] == "local":
+			sources = append(sources, Source{
+				Local: unquote(m[2]),
+			})
+			continue
+		}
+		// parse url
+		urlstr := unquote(m[2])
+		u, err := url.Parse(urlstr)
+		if err != nil {
+			return nil, fmt.Errorf("url %q: %w", urlstr, ErrInvalidSrc)
+		}
+		// parse format and tech hints
+		var hint string
+		var tech []string
+		if v := splitList(m[3]); len(v) != 0 {
+			hint = strings.ToLower(unquote(strings.TrimSpace(v[0])))
+		}
+		if s, ok := strings.CutSuffix(hint, "-variations"); ok {
+			hint, tech = s, append(tech, "variations")
+		}
+		for _, s := range splitList(m[4]) {
+			if s = strings.ToLower(unquote(strings.TrimSpace(s))); !contains(tech, s) {
+				tech = append(tech, s)
+			}
+		}
+		// determine format from file extension
+		format := Format(strings.ToLower(strings.TrimPrefix(path.Ext(path.Base(u.Path)), ".")))
+		if format == "" {
+			format = formatName(hint)
+		}
+		sources = append(sources, Source{
+			URL:    urlstr,
+			Format: format,
+			Tech:   tech,
+		})
 	}
-	// determine file extension
-	fileExt := strings.ToLower(strings.TrimPrefix(path.Ext(path.Base(u.Path)), "."))
-	if fileExt == "" {
-		fileExt = m[0][2]
+	return sources, nil
+}
+
+// srcRE matches a src entry.
+var srcRE = regexp.MustCompile(`(?s)^(url|local)\(\s*(.+?)\s*\)(?:\s*format\(\s*([^\)]+?)\s*\))?(?:\s*tech\(\s*([^\)]+?)\s*\))?$`)
+
+// unquote removes surrounding quotes from s.
+func unquote(s string) string {
+	if len(s) > 1 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
 	}
-	return m[0][1], fileExt, nil
+	return s
 }
 
-// srcRE matches src.
-var srcRE = regexp.MustCompile(`(?m)^url\(([^\)]+)\)(?:\s+format\('([^']+)'\))?$`)
+// formatName returns the format for a file extension or css format hint.
+func formatName(s string) Format {
+	if format, err := ParseFormat(s); err == nil {
+		return format
+	}
+	return Format(strings.ToLower(s))
+}
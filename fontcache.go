@@ -0,0 +1,111 @@
+package webfonts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+// FontCacheHosts are the hosts whose font files are immutable, and are stored
+// in the font cache without expiration (see WithFontCacheDir).
+var FontCacheHosts = []string{
+	"fonts.gstatic.com",
+	"fonts.gstatic.cn",
+	"gstatic.loli.net",
+	"fonts-gstatic.lug.ustc.edu.cn",
+}
+
+// fontCacheTransport is a http transport that stores font files retrieved
+// from immutable hosts (see FontCacheHosts) in a content-addressed store,
+// keyed by the sha256 hash of the font file's url. Cached font files never
+// expire.
+//
+// Requests for font files not in the store are made using the fetch
+// transport, bypassing the http cache. All other requests are made using the
+// transport.
+type fontCacheTransport struct {
+	dir       string
+	transport http.RoundTripper
+	fetch     http.RoundTripper
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *fontCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" || !contains(FontCacheHosts, req.URL.Hostname()) {
+		return t.transport.RoundTrip(req)
+	}
+	name := t.path(req.URL.String(), req.URL.Path)
+	if buf, err := ioutil.ReadFile(name); err == nil {
+		return fontCacheResponse(req, buf), nil
+	}
+	// retrieve
+	res, err := t.fetch.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusOK {
+		return res, err
+	}
+	defer res.Body.Close()
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.store(name, buf); err != nil {
+		return nil, err
+	}
+	return fontCacheResponse(req, buf), nil
+}
+
+// path returns the store path for the url.
+func (t *fontCacheTransport) path(urlstr, urlpath string) string {
+	sum := sha256.Sum256([]byte(urlstr))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(t.dir, key[:2], key[2:]+path.Ext(urlpath))
+}
+
+// store atomically writes the font file to the store.
+func (t *fontCacheTransport) store(name string, buf []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// fontCacheResponse builds a response for a cached font file.
+func fontCacheResponse(req *http.Request, buf []byte) *http.Response {
+	contentType := formatName(path.Ext(req.URL.Path)).MIMEType()
+	if contentType == "" {
+		contentType = http.DetectContentType(buf)
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type":   []string{contentType},
+			"Content-Length": []string{strconv.Itoa(len(buf))},
+		},
+		Body:          ioutil.NopCloser(bytes.NewReader(buf)),
+		ContentLength: int64(len(buf)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,92 @@
+package webfonts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is a font format.
+type Format string
+
+// Font formats.
+const (
+	FormatWOFF2 Format = "woff2"
+	FormatWOFF  Format = "woff"
+	FormatTTF   Format = "ttf"
+	FormatOTF   Format = "otf"
+	FormatEOT   Format = "eot"
+	FormatSVG   Format = "svg"
+)
+
+// ParseFormat parses a font format from a format name, file extension (ie,
+// ".ttf"), or css format hint (ie, "truetype").
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "woff2":
+		return FormatWOFF2, nil
+	case "woff":
+		return FormatWOFF, nil
+	case "ttf", "truetype":
+		return FormatTTF, nil
+	case "otf", "opentype":
+		return FormatOTF, nil
+	case "eot", "embedded-opentype":
+		return FormatEOT, nil
+	case "svg":
+		return FormatSVG, nil
+	}
+	return "", fmt.Errorf("%q: %w", s, ErrInvalidFormat)
+}
+
+// String satisfies the fmt.Stringer interface.
+func (format Format) String() string {
+	return string(format)
+}
+
+// MIMEType returns the mime type for the format, or the empty string for an
+// unknown format.
+func (format Format) MIMEType() string {
+	switch format {
+	case FormatWOFF2:
+		return "font/woff2"
+	case FormatWOFF:
+		return "font/woff"
+	case FormatTTF:
+		return "font/ttf"
+	case FormatOTF:
+		return "font/otf"
+	case FormatEOT:
+		return "application/vnd.ms-fontobject"
+	case FormatSVG:
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// ContentType returns the http content type for the format, defaulting to
+// application/octet-stream for an unknown format.
+func (format Format) ContentType() string {
+	if typ := format.MIMEType(); typ != "" {
+		return typ
+	}
+	return "application/octet-stream"
+}
+
+// CSSFormat returns the css format hint for the format (ie, "truetype"), as
+// used in a @font-face src descriptor's format().
+func (format Format) CSSFormat() string {
+	switch format {
+	case FormatTTF:
+		return "truetype"
+	case FormatOTF:
+		return "opentype"
+	case FormatEOT:
+		return "embedded-opentype"
+	}
+	return string(format)
+}
+
+// Extension returns the file extension for the format (ie, ".woff2").
+func (format Format) Extension() string {
+	return "." + string(format)
+}
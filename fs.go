@@ -0,0 +1,215 @@
+package webfonts
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+// BuildFS retrieves the font files for the font faces, returning a file
+// system containing the generated stylesheets and font files.
+func BuildFS(ctx context.Context, fonts []Font, opts ...ClientOption) (fs.FS, error) {
+	return NewClient(opts...).BuildFS(ctx, fonts)
+}
+
+// BuildFS retrieves the font files for the font faces, returning a file
+// system containing the generated stylesheets (<family>.css) and font files.
+// Stylesheets refer to the font files using relative urls.
+//
+// When integrity hashes are enabled (see WithIntegrity), the file system
+// includes the subresource integrity manifest.
+//
+// The returned file system can be passed to http.FS, or written to disk.
+func (cl *Client) BuildFS(ctx context.Context, fonts []Font, opts ...RouteOption) (fs.FS, error) {
+	// initialize
+	if err := cl.init(ctx); err != nil {
+		return nil, err
+	}
+	if cl.cl == nil {
+		return nil, ErrClientUninitialized
+	}
+	// build routes
+	now := time.Now()
+	m := make(memFS)
+	var routes []Route
+	if err := BuildRoutes("", fonts, func(family string, buf []byte, r []Route) error {
+		m[family+".css"] = &memFile{name: family + ".css", buf: buf, mod: now}
+		routes = append(routes, r...)
+		return nil
+	}, opts...); err != nil {
+		return nil, err
+	}
+	// retrieve
+	files := make([][]byte, len(routes))
+	if err := parallel(cl.concurrency, len(routes), func(i int) error {
+		var err error
+		files[i], err = cl.download(ctx, routes[i].URL)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	for i, route := range routes {
+		m[route.Path] = &memFile{name: route.Path, buf: files[i], mod: now}
+		routes[i].ByteSize = int64(len(files[i]))
+		if cl.integrity {
+			routes[i].Integrity = Integrity(files[i])
+		}
+	}
+	if cl.integrity {
+		buf, err := buildIntegrityManifest(routes)
+		if err != nil {
+			return nil, err
+		}
+		m[IntegrityManifest] = &memFile{name: IntegrityManifest, buf: buf, mod: now}
+	}
+	return m, nil
+}
+
+// memFS is a flat, in-memory, read-only file system.
+type memFS map[string]*memFile
+
+// Open satisfies the fs.FS interface.
+func (m memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &memDir{m: m}, nil
+	}
+	f, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memReader{memFile: f, r: bytes.NewReader(f.buf)}, nil
+}
+
+// ReadFile satisfies the fs.ReadFileFS interface.
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	f, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), f.buf...), nil
+}
+
+// ReadDir satisfies the fs.ReadDirFS interface.
+func (m memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.entries(), nil
+}
+
+// entries returns the sorted directory entries for the file system.
+func (m memFS) entries() []fs.DirEntry {
+	var entries []fs.DirEntry
+	for _, f := range m {
+		entries = append(entries, fs.FileInfoToDirEntry(f))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+// memFile is an in-memory file.
+type memFile struct {
+	name string
+	buf  []byte
+	mod  time.Time
+}
+
+// Name satisfies the fs.FileInfo interface.
+func (f *memFile) Name() string { return f.name }
+
+// Size satisfies the fs.FileInfo interface.
+func (f *memFile) Size() int64 { return int64(len(f.buf)) }
+
+// Mode satisfies the fs.FileInfo interface.
+func (f *memFile) Mode() fs.FileMode { return 0o444 }
+
+// ModTime satisfies the fs.FileInfo interface.
+func (f *memFile) ModTime() time.Time { return f.mod }
+
+// IsDir satisfies the fs.FileInfo interface.
+func (f *memFile) IsDir() bool { return false }
+
+// Sys satisfies the fs.FileInfo interface.
+func (f *memFile) Sys() interface{} { return nil }
+
+// memReader is an open in-memory file.
+type memReader struct {
+	*memFile
+	r *bytes.Reader
+}
+
+// Stat satisfies the fs.File interface.
+func (f *memReader) Stat() (fs.FileInfo, error) { return f.memFile, nil }
+
+// Read satisfies the fs.File interface.
+func (f *memReader) Read(p []byte) (int, error) { return f.r.Read(p) }
+
+// Seek satisfies the io.Seeker interface.
+func (f *memReader) Seek(offset int64, whence int) (int64, error) { return f.r.Seek(offset, whence) }
+
+// Close satisfies the fs.File interface.
+func (f *memReader) Close() error { return nil }
+
+// memDir is the open root directory of an in-memory file system.
+type memDir struct {
+	m       memFS
+	entries []fs.DirEntry
+	off     int
+}
+
+// Name satisfies the fs.FileInfo interface.
+func (d *memDir) Name() string { return "." }
+
+// Size satisfies the fs.FileInfo interface.
+func (d *memDir) Size() int64 { return 0 }
+
+// Mode satisfies the fs.FileInfo interface.
+func (d *memDir) Mode() fs.FileMode { return fs.ModeDir | 0o555 }
+
+// ModTime satisfies the fs.FileInfo interface.
+func (d *memDir) ModTime() time.Time { return time.Time{} }
+
+// IsDir satisfies the fs.FileInfo interface.
+func (d *memDir) IsDir() bool { return true }
+
+// Sys satisfies the fs.FileInfo interface.
+func (d *memDir) Sys() interface{} { return nil }
+
+// Stat satisfies the fs.File interface.
+func (d *memDir) Stat() (fs.FileInfo, error) { return d, nil }
+
+// Read satisfies the fs.File interface.
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: ".", Err: fs.ErrInvalid}
+}
+
+// Close satisfies the fs.File interface.
+func (d *memDir) Close() error { return nil }
+
+// ReadDir satisfies the fs.ReadDirFile interface.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		d.entries = d.m.entries()
+	}
+	rem := d.entries[d.off:]
+	if n <= 0 {
+		d.off = len(d.entries)
+		return rem, nil
+	}
+	if len(rem) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rem) {
+		n = len(rem)
+	}
+	d.off += n
+	return rem[:n], nil
+}
@@ -0,0 +1,493 @@
+package webfonts
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"golang.org/x/sync/singleflight"
+)
+
+// Handler is a http handler that serves font stylesheets and font files.
+//
+// Stylesheets are served as <prefix><family>.css, and font files are served
+// as <prefix><route path>. Content is served using http.ServeContent, which
+// handles Range, conditional (If-None-Match, If-Modified-Since), and HEAD
+// requests.
+type Handler struct {
+	cl                     *Client
+	clientOpts             []ClientOption
+	prefix                 string
+	queryOpts              []QueryOption
+	routeOpts              []RouteOption
+	all                    bool
+	fontCacheControl       string
+	stylesheetCacheControl string
+	compress               bool
+	origins                []string
+	lazy                   bool
+	group                  singleflight.Group
+	mu                     sync.RWMutex
+	families               map[string]string
+	paths                  map[string][]string
+	stylesheets            map[string]handlerFile
+	files                  map[string]handlerFile
+}
+
+// handlerFile is a stylesheet or font file served by the handler.
+type handlerFile struct {
+	contentType string
+	url         string
+	etag        string
+	modtime     time.Time
+	buf         []byte
+	encoded     map[string][]byte
+}
+
+// newHandlerFile creates a handler file for the content type, source url,
+// and data.
+func newHandlerFile(contentType, url string, buf []byte) handlerFile {
+	return handlerFile{
+		contentType: contentType,
+		url:         url,
+		etag:        etag(buf),
+		modtime:     time.Now(),
+		buf:         buf,
+	}
+}
+
+// etag returns a strong entity tag for the data, based on the data's sha256
+// hash.
+func etag(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return fmt.Sprintf(`"%x"`, sum[:16])
+}
+
+// compress compresses the handler file's data using each of the content
+// encodings.
+func (f *handlerFile) compress() error {
+	f.encoded = make(map[string][]byte, len(contentEncodings))
+	for _, encoding := range contentEncodings {
+		var b bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "br":
+			w = brotli.NewWriterLevel(&b, brotli.BestCompression)
+		case "gzip":
+			w, _ = gzip.NewWriterLevel(&b, gzip.BestCompression)
+		}
+		if _, err := w.Write(f.buf); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		f.encoded[encoding] = b.Bytes()
+	}
+	return nil
+}
+
+// negotiate returns the content encoding, entity tag, and data of the handler
+// file for the request's Accept-Encoding.
+func (f handlerFile) negotiate(req *http.Request) (string, string, []byte) {
+	if len(f.encoded) == 0 {
+		return "", f.etag, f.buf
+	}
+	accepted := make(map[string]bool)
+	for _, s := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		encoding, params, _ := strings.Cut(s, ";")
+		q := 1.0
+		if _, v, ok := strings.Cut(params, "q="); ok {
+			q, _ = strconv.ParseFloat(strings.TrimSpace(v), 64)
+		}
+		accepted[strings.ToLower(strings.TrimSpace(encoding))] = q > 0
+	}
+	for _, encoding := range contentEncodings {
+		if ok, exists := accepted[encoding]; ok || !exists && accepted["*"] {
+			return encoding, strings.TrimSuffix(f.etag, `"`) + "-" + encoding + `"`, f.encoded[encoding]
+		}
+	}
+	return "", f.etag, f.buf
+}
+
+// contentEncodings are the content encodings used for stylesheets, in order
+// of preference.
+var contentEncodings = []string{"br", "gzip"}
+
+// Default handler cache control values.
+const (
+	// DefaultFontCacheControl is the default Cache-Control for font files.
+	// Font file paths are derived from the font file's src url, so font files
+	// are cached indefinitely.
+	DefaultFontCacheControl = "public, max-age=31536000, immutable"
+	// DefaultStylesheetCacheControl is the default Cache-Control for
+	// stylesheets.
+	DefaultStylesheetCacheControl = "public, max-age=86400"
+)
+
+// NewHandler creates a new http handler that serves the font stylesheets and
+// font files for the specified families.
+func NewHandler(ctx context.Context, families []string, opts ...HandlerOption) (*Handler, error) {
+	h := &Handler{
+		prefix:                 "/",
+		fontCacheControl:       DefaultFontCacheControl,
+		stylesheetCacheControl: DefaultStylesheetCacheControl,
+		compress:               true,
+		families:               make(map[string]string),
+		paths:                  make(map[string][]string),
+		stylesheets:            make(map[string]handlerFile),
+		files:                  make(map[string]handlerFile),
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	if h.cl == nil {
+		h.cl = NewClient(h.clientOpts...)
+	}
+	if !strings.HasSuffix(h.prefix, "/") {
+		h.prefix += "/"
+	}
+	if err := h.Add(ctx, families...); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Add retrieves the font faces and font files for the specified families,
+// adding them to the handler. Families already served by the handler are
+// replaced. When the query includes effects (see WithEffects), the font
+// effect rules are included in each family's stylesheet.
+func (h *Handler) Add(ctx context.Context, families ...string) error {
+	// retrieve faces
+	res := make([][]Font, len(families))
+	if err := parallel(h.cl.concurrency, len(families), func(i int) error {
+		var err error
+		if h.all {
+			res[i], err = h.cl.All(ctx, families[i], h.queryOpts...)
+		} else {
+			res[i], err = h.cl.Faces(ctx, families[i], h.queryOpts...)
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	var fonts []Font
+	for _, v := range res {
+		fonts = append(fonts, v...)
+	}
+	// retrieve effects
+	routeOpts := h.routeOpts
+	if len(families) != 0 && NewQuery(families[0], h.queryOpts...).Effects != nil {
+		effects, err := h.cl.Effects(ctx, families[0], h.queryOpts...)
+		if err != nil {
+			return err
+		}
+		routeOpts = append([]RouteOption{WithEffectRules(effects...)}, h.routeOpts...)
+	}
+	// build routes
+	stylesheets := make(map[string][]byte)
+	var routes []Route
+	var routeFamilies []string
+	if err := BuildRoutes(h.prefix, fonts, func(family string, buf []byte, r []Route) error {
+		stylesheets[family] = buf
+		routes = append(routes, r...)
+		for range r {
+			routeFamilies = append(routeFamilies, family)
+		}
+		return nil
+	}, routeOpts...); err != nil {
+		return err
+	}
+	// retrieve files
+	files := make([]handlerFile, len(routes))
+	if err := parallel(h.cl.concurrency, len(routes), func(i int) error {
+		if h.lazy {
+			// retrieved on first request (see load)
+			files[i] = newHandlerFile(routes[i].Format.ContentType(), routes[i].URL, nil)
+			return nil
+		}
+		buf, err := h.cl.download(ctx, routes[i].URL)
+		if err != nil {
+			return err
+		}
+		files[i] = newHandlerFile(routes[i].Format.ContentType(), routes[i].URL, buf)
+		return nil
+	}); err != nil {
+		return err
+	}
+	// compress stylesheets
+	stylesheetFiles := make(map[string]handlerFile, len(stylesheets))
+	for family, buf := range stylesheets {
+		f := newHandlerFile("text/css; charset=utf-8", "", buf)
+		if h.compress {
+			if err := f.compress(); err != nil {
+				return err
+			}
+		}
+		stylesheetFiles[family] = f
+	}
+	// add
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for family, f := range stylesheetFiles {
+		h.remove(family)
+		urlpath := h.StylesheetPath(family)
+		h.families[family] = urlpath
+		h.stylesheets[urlpath] = f
+	}
+	for i, route := range routes {
+		urlpath := h.prefix + route.Path
+		h.files[urlpath] = files[i]
+		h.paths[routeFamilies[i]] = append(h.paths[routeFamilies[i]], urlpath)
+	}
+	return nil
+}
+
+// Remove removes the stylesheets and font files for the specified families
+// from the handler.
+func (h *Handler) Remove(families ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, family := range families {
+		h.remove(family)
+	}
+}
+
+// remove removes the stylesheet and font files for the family. The handler's
+// lock must be held.
+func (h *Handler) remove(family string) {
+	urlpath, ok := h.families[family]
+	if !ok {
+		return
+	}
+	delete(h.stylesheets, urlpath)
+	for _, urlpath := range h.paths[family] {
+		delete(h.files, urlpath)
+	}
+	delete(h.families, family)
+	delete(h.paths, family)
+}
+
+// StylesheetPath returns the url path of the stylesheet for the family.
+func (h *Handler) StylesheetPath(family string) string {
+	return path.Join(h.prefix, family) + ".css"
+}
+
+// Families returns the families served by the handler, mapped to the url path
+// of their stylesheet.
+func (h *Handler) Families() map[string]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	m := make(map[string]string, len(h.families))
+	for family, urlpath := range h.families {
+		m[family] = urlpath
+	}
+	return m
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (h *Handler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	h.mu.RLock()
+	stylesheet, isStylesheet := h.stylesheets[req.URL.Path]
+	f, isFile := h.files[req.URL.Path]
+	h.mu.RUnlock()
+	cacheControl := h.fontCacheControl
+	switch {
+	case isStylesheet:
+		f, cacheControl = stylesheet, h.stylesheetCacheControl
+	case !isFile:
+		http.NotFound(res, req)
+		return
+	}
+	// cors
+	allowed := h.cors(res, req)
+	if req.Method == http.MethodOptions {
+		res.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		if allowed {
+			res.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			if headers := req.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				res.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			res.Header().Set("Access-Control-Max-Age", "86400")
+		}
+		res.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		res.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	// load lazily fetched font files
+	if f.buf == nil {
+		var err error
+		if f, err = h.load(req.Context(), req.URL.Path, f); err != nil {
+			code := http.StatusBadGateway
+			var se *StatusError
+			if errors.As(err, &se) && se.Code < http.StatusInternalServerError {
+				code = se.Code
+			}
+			http.Error(res, http.StatusText(code), code)
+			return
+		}
+	}
+	encoding, etag, buf := f.negotiate(req)
+	// headers
+	if len(f.encoded) != 0 {
+		res.Header().Add("Vary", "Accept-Encoding")
+	}
+	res.Header().Set("ETag", etag)
+	if cacheControl != "" {
+		res.Header().Set("Cache-Control", cacheControl)
+	}
+	res.Header().Set("Content-Type", f.contentType)
+	if encoding != "" {
+		res.Header().Set("Content-Encoding", encoding)
+	}
+	http.ServeContent(res, req, req.URL.Path, f.modtime, bytes.NewReader(buf))
+}
+
+// load retrieves a lazily fetched font file, adding it to the handler.
+// Concurrent loads of the same file are deduplicated (see share).
+func (h *Handler) load(ctx context.Context, urlpath string, f handlerFile) (handlerFile, error) {
+	v, err := share(ctx, &h.group, urlpath, h.cl.sharedTimeout(), func(ctx context.Context) (interface{}, error) {
+		buf, err := h.cl.download(ctx, f.url)
+		if err != nil {
+			return nil, err
+		}
+		file := newHandlerFile(f.contentType, f.url, buf)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// only add when the file was not removed or replaced
+		if prev, ok := h.files[urlpath]; ok && prev.url == f.url {
+			h.files[urlpath] = file
+		}
+		return file, nil
+	})
+	if err != nil {
+		return handlerFile{}, err
+	}
+	return v.(handlerFile), nil
+}
+
+// cors sets the cross-origin resource sharing headers for the request's
+// origin, returning true when the origin is allowed.
+func (h *Handler) cors(res http.ResponseWriter, req *http.Request) bool {
+	if len(h.origins) == 0 {
+		return false
+	}
+	if contains(h.origins, "*") {
+		res.Header().Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+	res.Header().Add("Vary", "Origin")
+	origin := req.Header.Get("Origin")
+	if origin == "" || !containsFold(h.origins, origin) {
+		return false
+	}
+	res.Header().Set("Access-Control-Allow-Origin", origin)
+	return true
+}
+
+// HandlerOption is a handler option.
+type HandlerOption func(*Handler)
+
+// WithHandlerClient is a handler option to set the client used by the
+// handler.
+func WithHandlerClient(cl *Client) HandlerOption {
+	return func(h *Handler) {
+		h.cl = cl
+	}
+}
+
+// WithHandlerClientOptions is a handler option to set the client options used
+// to create the handler's client.
+func WithHandlerClientOptions(opts ...ClientOption) HandlerOption {
+	return func(h *Handler) {
+		h.clientOpts = append(h.clientOpts, opts...)
+	}
+}
+
+// WithHandlerQueryOptions is a handler option to set the query options used
+// to retrieve each family.
+func WithHandlerQueryOptions(opts ...QueryOption) HandlerOption {
+	return func(h *Handler) {
+		h.queryOpts = append(h.queryOpts, opts...)
+	}
+}
+
+// WithHandlerRouteOptions is a handler option to set the route options used
+// to build the stylesheets.
+func WithHandlerRouteOptions(opts ...RouteOption) HandlerOption {
+	return func(h *Handler) {
+		h.routeOpts = append(h.routeOpts, opts...)
+	}
+}
+
+// WithHandlerPrefix is a handler option to set the url path prefix the
+// stylesheets and font files are served under (default: /).
+func WithHandlerPrefix(prefix string) HandlerOption {
+	return func(h *Handler) {
+		h.prefix = prefix
+	}
+}
+
+// WithHandlerCacheControl is a handler option to set the Cache-Control
+// header values for font files (default: DefaultFontCacheControl) and
+// stylesheets (default: DefaultStylesheetCacheControl). An empty value omits
+// the header.
+func WithHandlerCacheControl(fonts, stylesheets string) HandlerOption {
+	return func(h *Handler) {
+		h.fontCacheControl, h.stylesheetCacheControl = fonts, stylesheets
+	}
+}
+
+// WithHandlerCompression is a handler option to set whether stylesheets are
+// pre-compressed (gzip and brotli) and served using the encoding accepted by
+// the client (default: true). Font files are never compressed, as woff2 is
+// already compressed.
+func WithHandlerCompression(compress bool) HandlerOption {
+	return func(h *Handler) {
+		h.compress = compress
+	}
+}
+
+// WithHandlerCORS is a handler option to set the origins (ie,
+// https://example.com) allowed to use the served stylesheets and font files
+// cross-origin, via the Access-Control-Allow-Origin header. Use "*" to allow
+// all origins. Browsers require the header for font files served from a
+// different origin than the page, such as from a CDN or another subdomain.
+func WithHandlerCORS(origins ...string) HandlerOption {
+	return func(h *Handler) {
+		h.origins = append(h.origins, origins...)
+	}
+}
+
+// WithHandlerLazy is a handler option to retrieve font files on first request,
+// instead of when families are added to the handler. Retrieved font files are
+// kept by the handler, and concurrent requests for the same font file are
+// deduplicated. Reduces startup time when serving many families.
+func WithHandlerLazy() HandlerOption {
+	return func(h *Handler) {
+		h.lazy = true
+	}
+}
+
+// WithHandlerAllFormats is a handler option to retrieve and serve all common
+// font formats for each family (see Client.All).
+func WithHandlerAllFormats() HandlerOption {
+	return func(h *Handler) {
+		h.all = true
+	}
+}
@@ -0,0 +1,53 @@
+package webfonts_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kenshaw/webfonts"
+	"github.com/kenshaw/webfonts/webfontstest"
+)
+
+func TestHandlerLazyMethods(t *testing.T) {
+	f := webfontstest.New()
+	f.AddFamily(webfonts.Family{Name: "A", Variants: []string{"regular"}})
+	f.AddStylesheet("A", "", `@font-face {
+  font-family: 'A';
+  font-style: normal;
+  font-weight: 400;
+  src: url(https://example.com/a-400.woff2) format('woff2');
+}`)
+	// the font file is not added, so that any download fails
+	h, err := webfonts.NewHandler(context.Background(), []string{"A"},
+		webfonts.WithHandlerClient(f.Client(webfonts.WithAppCacheDir(t.TempDir()))),
+		webfonts.WithHandlerRouteOptions(webfonts.WithRouteNaming(func(webfonts.Font) string {
+			return "a.woff2"
+		})),
+		webfonts.WithHandlerCORS("*"),
+		webfonts.WithHandlerLazy(),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		method string
+		exp    int
+	}{
+		{http.MethodOptions, http.StatusNoContent},
+		{http.MethodPost, http.StatusMethodNotAllowed},
+		{http.MethodGet, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.method, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/a.woff2", nil)
+			req.Header.Set("Origin", "https://example.com")
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+			if res.Code != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, res.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package webfonts
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// HeadHTML renders the html <head> tags to embed the font families in a
+// page.
+//
+// By default, renders preconnect <link> tags for the provider's origins, and
+// a stylesheet <link> tag for each family hosted by the client's provider
+// (see WithProvider). When self-hosted (see WithHeadSelfHosted), renders a
+// stylesheet <link> tag for each family's stylesheet under the prefix, as
+// served by Handler. When using imports (see WithHeadImport), renders an
+// inline <style> tag with an @import rule for each stylesheet instead.
+func HeadHTML(families []string, opts ...HeadOption) (template.HTML, error) {
+	o := &headOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	// build stylesheet urls
+	var preconnect []headPreconnect
+	var stylesheets []string
+	switch {
+	case o.selfHosted:
+		prefix := o.prefix
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		for _, family := range families {
+			stylesheets = append(stylesheets, path.Join(prefix, family)+".css")
+		}
+	default:
+		cl := NewClient(o.clientOpts...)
+		origins := make(map[string]bool)
+		for _, family := range families {
+			urls := cl.provider.Stylesheet(cl, NewQuery(family, o.queryOpts...))
+			if len(urls) == 0 {
+				return "", &NotFoundError{Family: family}
+			}
+			stylesheets = append(stylesheets, urls[0])
+			u, err := url.Parse(urls[0])
+			if err != nil {
+				return "", err
+			}
+			if origin := u.Scheme + "://" + u.Host; u.Host != "" && !origins[origin] {
+				origins[origin] = true
+				preconnect = append(preconnect, headPreconnect{URL: origin})
+				if origin == string(MirrorGoogle) {
+					preconnect = append(preconnect, headPreconnect{URL: "https://fonts.gstatic.com", CrossOrigin: true})
+				}
+			}
+		}
+	}
+	// render
+	buf := new(bytes.Buffer)
+	if err := headTpl.Execute(buf, map[string]interface{}{
+		"preconnect":  preconnect,
+		"stylesheets": stylesheets,
+		"import":      o.imports,
+	}); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// HeadOption is a head html option.
+type HeadOption func(*headOptions)
+
+// headOptions are head html options.
+type headOptions struct {
+	clientOpts []ClientOption
+	queryOpts  []QueryOption
+	selfHosted bool
+	prefix     string
+	imports    bool
+}
+
+// headPreconnect is a preconnect origin.
+type headPreconnect struct {
+	URL         string
+	CrossOrigin bool
+}
+
+// WithHeadClientOptions is a head html option to set the client options used
+// to build the stylesheet urls (ie, WithProvider, WithMirrors).
+func WithHeadClientOptions(opts ...ClientOption) HeadOption {
+	return func(o *headOptions) {
+		o.clientOpts = append(o.clientOpts, opts...)
+	}
+}
+
+// WithHeadQueryOptions is a head html option to set the query options used to
+// build each family's stylesheet url.
+func WithHeadQueryOptions(opts ...QueryOption) HeadOption {
+	return func(o *headOptions) {
+		o.queryOpts = append(o.queryOpts, opts...)
+	}
+}
+
+// WithHeadSelfHosted is a head html option to link to self-hosted
+// stylesheets served under the url path prefix (see Handler).
+func WithHeadSelfHosted(prefix string) HeadOption {
+	return func(o *headOptions) {
+		o.selfHosted, o.prefix = true, prefix
+	}
+}
+
+// WithHeadImport is a head html option to render an inline <style> tag with
+// @import rules instead of stylesheet <link> tags.
+func WithHeadImport() HeadOption {
+	return func(o *headOptions) {
+		o.imports = true
+	}
+}
+
+// headTpl is the head html template.
+var headTpl = template.Must(template.New("head").Parse(`
+{{- range .preconnect -}}
+<link rel="preconnect" href="{{ .URL }}"{{ if .CrossOrigin }} crossorigin{{ end }}>
+{{ end -}}
+{{- if .import -}}
+<style>
+{{- range .stylesheets }}
+@import url({{ . }});
+{{- end }}
+</style>
+{{ else -}}
+{{- range .stylesheets -}}
+<link rel="stylesheet" href="{{ . }}">
+{{ end -}}
+{{- end -}}`))
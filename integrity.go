@@ -0,0 +1,35 @@
+package webfonts
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// IntegrityManifest is the file name of the subresource integrity manifest
+// written when integrity hashes are enabled (see WithIntegrity).
+//
+// The manifest is a json object mapping font file paths to their subresource
+// integrity values.
+const IntegrityManifest = "integrity.json"
+
+// Integrity returns the subresource integrity value (ie, "sha384-...") for the
+// data, suitable for use in an integrity= attribute.
+func Integrity(buf []byte) string {
+	sum := sha512.Sum384(buf)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// buildIntegrityManifest builds the subresource integrity manifest for the
+// routes.
+func buildIntegrityManifest(routes []Route) ([]byte, error) {
+	m := make(map[string]string, len(routes))
+	for _, route := range routes {
+		m[route.Path] = route.Integrity
+	}
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
@@ -0,0 +1,181 @@
+package webfonts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// localSource is a local mirror or bundle directory (see WithLocalSource).
+type localSource struct {
+	dir   string
+	m     *Manifest
+	files map[string]string
+	// shared are the srcs of font files in multiple formats (ie, woff2 font
+	// files converted when bundling, see WithBundleConvert).
+	shared map[string]bool
+}
+
+// loadLocalSource loads the manifest of the mirror or bundle in dir.
+func loadLocalSource(dir string) (*localSource, error) {
+	m, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	src := &localSource{
+		dir:    dir,
+		m:      m,
+		files:  make(map[string]string),
+		shared: make(map[string]bool),
+	}
+	formats := make(map[string]Format)
+	for _, family := range m.Families {
+		for _, file := range family.Files {
+			if format, ok := formats[file.Font.Src]; ok && format != file.Font.Format {
+				src.shared[file.Font.Src] = true
+			}
+			formats[file.Font.Src] = file.Font.Format
+		}
+	}
+	for _, family := range m.Families {
+		for _, file := range family.Files {
+			src.files[src.key(file.Font)] = filepath.Join(dir, filepath.FromSlash(file.Path))
+		}
+	}
+	return src, nil
+}
+
+// key returns the key for the font in the local source's files, the font's
+// src with the format appended when the src is shared by multiple formats.
+func (src *localSource) key(font Font) string {
+	if src.shared[font.Src] {
+		return font.Src + "#" + string(font.Format)
+	}
+	return font.Src
+}
+
+// available returns the families in the manifest.
+func (src *localSource) available(o *ListOptions) []Family {
+	var families []Family
+	for _, family := range src.m.Families {
+		if len(o.Families) != 0 && !containsFold(o.Families, family.Family) {
+			continue
+		}
+		f := Family{
+			Name:     family.Family,
+			Variants: family.Variants,
+			Version:  family.Version,
+			Files:    make(map[string]string),
+		}
+		if family.LastModified != nil {
+			f.LastModified = *family.LastModified
+		}
+		for _, file := range family.Files {
+			if file.Font.Subset != "" && !contains(f.Subsets, file.Font.Subset) {
+				f.Subsets = append(f.Subsets, file.Font.Subset)
+			}
+			for _, variant := range fontVariants(file.Font) {
+				f.Files[variant] = file.Font.Src
+			}
+		}
+		families = append(families, f)
+	}
+	return families
+}
+
+// get returns the font faces in the manifest matching the query and format.
+func (src *localSource) get(q *Query, format Format) ([]Font, error) {
+	families := q.Families
+	if len(families) == 0 {
+		families = []string{q.Family}
+	}
+	variants := []string{"regular"}
+	if q.Variants != nil {
+		variants = make([]string, len(q.Variants))
+		for i, variant := range q.Variants {
+			variants[i] = strings.ToLower(variant)
+			if weight, style, err := NormalizeVariant(variant); err == nil {
+				variants[i] = variantName(strconv.Itoa(weight), style)
+			}
+		}
+	}
+	p := &Provenance{
+		URL:    "file://" + filepath.ToSlash(filepath.Join(src.dir, ManifestName)),
+		Cached: true,
+		Time:   src.m.Generated,
+	}
+	var fonts []Font
+	for _, name := range families {
+		var found bool
+		for _, family := range src.m.Families {
+			if !strings.EqualFold(family.Family, name) {
+				continue
+			}
+			found = true
+			for _, file := range family.Files {
+				font := file.Font
+				switch {
+				case font.Format != format,
+					!containsAny(variants, fontVariants(font)),
+					q.Subsets != nil && font.Subset != "" && !contains(q.Subsets, font.Subset):
+					continue
+				}
+				if q.Display != "" {
+					font.Display = q.Display
+				}
+				font.Src, font.Axes, font.Provenance = src.key(font), q.Axes, p
+				fonts = append(fonts, font)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: %w", name, ErrFamilyNotFound)
+		}
+	}
+	return fonts, nil
+}
+
+// containsAny returns true when v contains any of z.
+func containsAny(v, z []string) bool {
+	for _, s := range z {
+		if contains(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold returns true when v contains s, ignoring case.
+func containsFold(v []string, s string) bool {
+	for _, z := range v {
+		if strings.EqualFold(z, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// localTransport is a http transport that serves the font files of a local
+// source, and refuses all other requests.
+type localTransport struct {
+	src *localSource
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *localTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name, ok := t.src.files[req.URL.String()]
+	if !ok || req.Method != "GET" {
+		return nil, fmt.Errorf("%s: %w", req.URL, ErrNotAvailableOffline)
+	}
+	buf, err := ioutil.ReadFile(name)
+	switch {
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf("%s: %w", req.URL, ErrNotAvailableOffline)
+	case err != nil:
+		return nil, err
+	}
+	return fontCacheResponse(req, buf), nil
+}
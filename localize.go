@@ -0,0 +1,97 @@
+package webfonts
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// LocalizeHTML retrieves the fonts referenced by the html document (see
+// ScanHTML), writing a bundle to dir for each referenced stylesheet, and
+// returning the html document with the references rewritten to the bundled
+// stylesheets. See Client.LocalizeHTML.
+func LocalizeHTML(ctx context.Context, r io.Reader, dir string, opts ...BundleOption) ([]byte, error) {
+	o := newBundleOptions(opts...)
+	return NewClient(o.clientOpts...).localizeHTML(ctx, r, dir, o)
+}
+
+// LocalizeHTML retrieves the fonts referenced by the html document (see
+// ScanHTML), writing a bundle (see Bundle) to a subdirectory of dir for each
+// referenced stylesheet, and returning the html document with the references
+// rewritten to the bundled stylesheets. Preconnect and dns-prefetch <link>
+// tags for fonts.googleapis.com and fonts.gstatic.com are removed, so that
+// browsers never contact Google.
+//
+// Rewritten references are the slash-separated path of the bundled
+// stylesheet, joined to dir (ie, fonts/roboto/fonts.css), and should be
+// relative to the html document.
+//
+// Client options set with WithBundleClientOptions are not supported, and
+// return ErrClientOptionsUnsupported.
+func (cl *Client) LocalizeHTML(ctx context.Context, r io.Reader, dir string, opts ...BundleOption) ([]byte, error) {
+	o := newBundleOptions(opts...)
+	if len(o.clientOpts) != 0 {
+		return nil, ErrClientOptionsUnsupported
+	}
+	return cl.localizeHTML(ctx, r, dir, o)
+}
+
+// localizeHTML localizes the html document, writing the bundles to dir.
+func (cl *Client) localizeHTML(ctx context.Context, r io.Reader, dir string, o *bundleOptions) ([]byte, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	urls, err := scanHTML(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	for _, urlstr := range urls {
+		q, err := ParseQuery(urlstr)
+		if err != nil {
+			return nil, err
+		}
+		families := q.Families
+		if len(families) == 0 {
+			families = []string{q.Family}
+		}
+		q.Family, q.Families = "", nil
+		// bundle
+		var names []string
+		for _, family := range families {
+			names = append(names, mirrorDir(family))
+		}
+		name := strings.Join(names, "-")
+		bo := *o
+		bo.queryOpts = append(append([]QueryOption(nil), o.queryOpts...), withQuery(q))
+		if err := cl.bundle(ctx, families, filepath.Join(dir, name), &bo); err != nil {
+			return nil, err
+		}
+		// rewrite
+		href := path.Join(filepath.ToSlash(dir), name, o.stylesheet)
+		buf = bytes.ReplaceAll(buf, []byte(html.EscapeString(urlstr)), []byte(href))
+		buf = bytes.ReplaceAll(buf, []byte(urlstr), []byte(href))
+	}
+	// remove preconnect links
+	return linkRE.ReplaceAllFunc(buf, func(b []byte) []byte {
+		if preconnectRE.Match(b) && googleHostRE.Match(b) {
+			return nil
+		}
+		return b
+	}), nil
+}
+
+// linkRE matches <link> tags.
+var linkRE = regexp.MustCompile(`(?i)<link\b[^>]*>`)
+
+// preconnectRE matches preconnect and dns-prefetch rel attributes.
+var preconnectRE = regexp.MustCompile(`(?i)\brel\s*=\s*['"]?(?:preconnect|dns-prefetch)\b`)
+
+// googleHostRE matches the google fonts hosts.
+var googleHostRE = regexp.MustCompile(`(?i)//fonts\.(?:googleapis|gstatic)\.com\b`)
@@ -0,0 +1,67 @@
+package webfonts
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// logTransport is a http transport that logs upstream (network) requests as
+// structured events.
+type logTransport struct {
+	transport http.RoundTripper
+	logger    *slog.Logger
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		t.logger.WarnContext(req.Context(), "request failed",
+			slog.String("method", req.Method),
+			slog.String("url", req.URL.String()),
+			slog.Duration("duration", time.Since(start)),
+			slog.Any("error", err),
+		)
+		return nil, err
+	}
+	t.logger.DebugContext(req.Context(), "request",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Int("status", res.StatusCode),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return res, nil
+}
+
+// logRetrieved logs a retrieved stylesheet or font file.
+func (cl *Client) logRetrieved(kind string, p *Provenance, n int) {
+	if cl.logger == nil {
+		return
+	}
+	cl.logger.Debug("retrieved",
+		slog.String("kind", kind),
+		slog.String("url", p.URL),
+		slog.Bool("cached", p.Cached),
+		slog.Bool("revalidated", p.Revalidated),
+		slog.Int("size", n),
+	)
+}
+
+// logParse logs a stylesheet parse failure, or a warning when the stylesheet
+// has no font faces.
+func (cl *Client) logParse(p *Provenance, fonts []Font, err error) {
+	switch {
+	case cl.logger == nil:
+	case err != nil:
+		cl.logger.Error("unable to parse stylesheet",
+			slog.String("url", p.URL),
+			slog.Any("error", err),
+		)
+	case len(fonts) == 0:
+		cl.logger.Warn("stylesheet has no font faces",
+			slog.String("url", p.URL),
+		)
+	}
+}
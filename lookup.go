@@ -0,0 +1,131 @@
+package webfonts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Lookup looks up the specified family in the available families, returning
+// a NotFoundError with suggested family names when the family is not
+// available. The available families are retrieved with Available, and are
+// cached when the client has a catalog ttl (see WithCatalogTTL).
+func (cl *Client) Lookup(ctx context.Context, family string) (*Family, error) {
+	families, err := cl.Available(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range families {
+		if families[i].Name == family {
+			return &families[i], nil
+		}
+	}
+	return nil, &NotFoundError{
+		Family:      family,
+		Suggestions: suggest(family, families, 5),
+	}
+}
+
+// NotFoundError is a family not found error.
+type NotFoundError struct {
+	Family      string
+	Suggestions []string
+}
+
+// Error satisfies the error interface.
+func (err *NotFoundError) Error() string {
+	s := fmt.Sprintf("family %q not found", err.Family)
+	if len(err.Suggestions) != 0 {
+		s += fmt.Sprintf(" (did you mean %q?)", strings.Join(err.Suggestions, `", "`))
+	}
+	return s
+}
+
+// Is satisfies the errors.Is interface. A not found error is always
+// ErrFamilyNotFound.
+func (err *NotFoundError) Is(target error) bool {
+	return target == ErrFamilyNotFound
+}
+
+// suggest returns up to n family names similar to family.
+func suggest(family string, families []Family, n int) []string {
+	type match struct {
+		name string
+		dist int
+	}
+	key := normalizeName(family)
+	max := len(key)/3 + 1
+	var matches []match
+	for _, f := range families {
+		name := normalizeName(f.Name)
+		var dist int
+		switch {
+		case name == key:
+		case strings.HasPrefix(name, key) || strings.HasPrefix(key, name):
+			dist = 1
+		default:
+			if dist = levenshtein(key, name) + 1; dist > max {
+				continue
+			}
+		}
+		matches = append(matches, match{f.Name, dist})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+	var v []string
+	for i := 0; i < len(matches) && i < n; i++ {
+		v = append(v, matches[i].name)
+	}
+	return v
+}
+
+// normalizeName normalizes a family name for comparison, removing case and
+// non-alphanumeric characters.
+func normalizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// levenshtein returns the levenshtein edit distance between a and b.
+func levenshtein(a, b string) int {
+	x, y := []rune(a), []rune(b)
+	prev := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		cur := make([]int, len(y)+1)
+		cur[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(y)]
+}
+
+// minInt returns the minimum of v.
+func minInt(v ...int) int {
+	m := v[0]
+	for _, z := range v[1:] {
+		if z < m {
+			m = z
+		}
+	}
+	return m
+}
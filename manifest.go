@@ -0,0 +1,158 @@
+package webfonts
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestName is the file name of the manifest written to bundles (see
+// Bundle) and mirrors (see Client.Mirror).
+const ManifestName = "manifest.json"
+
+// ManifestVersion is the manifest format version.
+const ManifestVersion = 1
+
+// ManifestSchema is the json schema for the manifest format.
+//
+//go:embed manifest.schema.json
+var ManifestSchema []byte
+
+// Manifest describes the families and font files of a bundle or mirror.
+//
+// Manifests are written with families sorted by name and files sorted by path,
+// and without font provenance, so that manifests can be consumed and compared
+// deterministically.
+type Manifest struct {
+	// Version is the manifest format version.
+	Version int `json:"version"`
+	// Generated is the time the manifest was generated.
+	Generated time.Time `json:"generated"`
+	// Query are the query options used to retrieve each family.
+	Query *Query `json:"query,omitempty"`
+	// All is whether or not all common font formats were retrieved for each
+	// family.
+	All bool `json:"all,omitempty"`
+	// Filter are the options used to retrieve the available families.
+	Filter *ListOptions `json:"filter,omitempty"`
+	// Stylesheet is the path of the stylesheet, if any.
+	Stylesheet string `json:"stylesheet,omitempty"`
+	// Families are the families.
+	Families []ManifestFamily `json:"families"`
+}
+
+// ManifestFamily describes a family in a manifest.
+type ManifestFamily struct {
+	// Family is the family name.
+	Family string `json:"family"`
+	// Version is the family version, when known.
+	Version string `json:"version,omitempty"`
+	// LastModified is the family's last modified date, when known.
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	// Variants are the family's variants (ie, "regular", "700italic").
+	Variants []string `json:"variants,omitempty"`
+	// Files are the family's font files. File paths are slash-separated, and
+	// relative to the manifest.
+	Files []FileInfo `json:"files"`
+}
+
+// current returns true when the manifest family has the same version and last
+// modified date as the family.
+func (f ManifestFamily) current(family Family) bool {
+	if f.LastModified == nil {
+		return f.Version == family.Version && family.LastModified.IsZero()
+	}
+	return f.Version == family.Version && f.LastModified.Equal(family.LastModified)
+}
+
+// exists returns true when the manifest family's font files exist in dir.
+func (f ManifestFamily) exists(dir string) bool {
+	for _, file := range f.Files {
+		fi, err := os.Stat(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil || fi.Size() != file.Size {
+			return false
+		}
+	}
+	return len(f.Files) != 0
+}
+
+// LoadManifest reads the manifest in dir.
+func LoadManifest(dir string) (*Manifest, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(buf, m); err != nil {
+		return nil, err
+	}
+	if m.Version < 1 || ManifestVersion < m.Version {
+		return nil, fmt.Errorf("%s: %w %d", ManifestName, ErrManifestVersion, m.Version)
+	}
+	return m, nil
+}
+
+// WriteManifest atomically writes the manifest to dir, sorting the manifest's
+// families and files. When not set, the manifest's version and generation
+// time are set.
+func WriteManifest(dir string, m *Manifest) error {
+	// normalize
+	if m.Version == 0 {
+		m.Version = ManifestVersion
+	}
+	if m.Generated.IsZero() {
+		m.Generated = time.Now().UTC()
+	}
+	if m.Families == nil {
+		m.Families = make([]ManifestFamily, 0)
+	}
+	sort.Slice(m.Families, func(i, j int) bool {
+		return m.Families[i].Family < m.Families[j].Family
+	})
+	for _, family := range m.Families {
+		for i := range family.Files {
+			family.Files[i].Font.Provenance = nil
+		}
+		sort.Slice(family.Files, func(i, j int) bool {
+			return family.Files[i].Path < family.Files[j].Path
+		})
+	}
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write
+	f, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(buf, '\n')); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, ManifestName))
+}
+
+// withQuery is a query option to set the query's options from the manifest
+// query, retaining the query's family and, when not set in the manifest
+// query, variants.
+func withQuery(mq *Query) QueryOption {
+	return func(q *Query) {
+		family, variants := q.Family, q.Variants
+		*q = *mq
+		q.Family = family
+		if q.Variants == nil {
+			q.Variants = variants
+		}
+	}
+}
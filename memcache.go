@@ -0,0 +1,138 @@
+package webfonts
+
+import (
+	"bytes"
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryCacheTransport is a http transport that caches successful GET
+// responses in memory, evicting the least recently used responses when the
+// cached response bodies exceed the maximum size.
+type memoryCacheTransport struct {
+	transport http.RoundTripper
+	maxBytes  int64
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	size  int64
+	lru   *list.List
+	items map[string]*list.Element
+}
+
+// memoryCacheEntry is a cached response.
+type memoryCacheEntry struct {
+	key    string
+	status int
+	header http.Header
+	body   []byte
+	added  time.Time
+}
+
+// newMemoryCacheTransport creates a new in-memory cache transport.
+func newMemoryCacheTransport(transport http.RoundTripper, maxBytes int64, ttl time.Duration) *memoryCacheTransport {
+	return &memoryCacheTransport{
+		transport: transport,
+		maxBytes:  maxBytes,
+		ttl:       ttl,
+		lru:       list.New(),
+		items:     make(map[string]*list.Element),
+	}
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *memoryCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" {
+		return t.transport.RoundTrip(req)
+	}
+	key := req.URL.String()
+	if ua := req.Header.Get("User-Agent"); ua != "" {
+		key += "\n" + ua
+	}
+	if e := t.get(key); e != nil {
+		return e.response(req), nil
+	}
+	// retrieve
+	res, err := t.transport.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusOK {
+		return res, err
+	}
+	defer res.Body.Close()
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	e := &memoryCacheEntry{
+		key:    key,
+		status: res.StatusCode,
+		header: res.Header.Clone(),
+		body:   buf,
+		added:  time.Now(),
+	}
+	t.add(e)
+	return e.response(req), nil
+}
+
+// get returns the cached entry for the key, if any.
+func (t *memoryCacheTransport) get(key string) *memoryCacheEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	el, ok := t.items[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*memoryCacheEntry)
+	if t.ttl > 0 && time.Since(e.added) > t.ttl {
+		t.remove(el)
+		return nil
+	}
+	t.lru.MoveToFront(el)
+	return e
+}
+
+// add adds the entry to the cache, evicting the least recently used entries
+// as necessary.
+func (t *memoryCacheTransport) add(e *memoryCacheEntry) {
+	size := int64(len(e.body))
+	if size > t.maxBytes {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if el, ok := t.items[e.key]; ok {
+		t.remove(el)
+	}
+	for t.size+size > t.maxBytes && t.lru.Len() != 0 {
+		t.remove(t.lru.Back())
+	}
+	t.items[e.key] = t.lru.PushFront(e)
+	t.size += size
+}
+
+// remove removes the element from the cache. The lock must be held.
+func (t *memoryCacheTransport) remove(el *list.Element) {
+	e := t.lru.Remove(el).(*memoryCacheEntry)
+	delete(t.items, e.key)
+	t.size -= int64(len(e.body))
+}
+
+// response builds a response for the entry.
+func (e *memoryCacheEntry) response(req *http.Request) *http.Response {
+	header := e.header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(e.body)))
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,63 @@
+package webfonts
+
+import (
+	"net/http"
+	"time"
+)
+
+// Collector collects client metrics. See WithMetrics.
+//
+// Collector methods may be called concurrently.
+type Collector interface {
+	// Request is called for each upstream (network) request, with the
+	// request's host, the response status code (0 when the request failed),
+	// and the request's duration.
+	Request(host string, code int, d time.Duration)
+	// Cache is called for each retrieved stylesheet or font file, with the
+	// kind of file retrieved (see KindStylesheet, KindFont) and whether the
+	// file was served from a cache.
+	Cache(kind string, hit bool)
+	// Downloaded is called with the size of each stylesheet or font file
+	// retrieved from the network.
+	Downloaded(kind string, n int64)
+	// ParseError is called when a retrieved stylesheet fails to parse.
+	ParseError()
+}
+
+// Metric file kinds.
+const (
+	KindStylesheet = "stylesheet"
+	KindFont       = "font"
+)
+
+// collect logs and collects the retrieval metrics for a stylesheet or font
+// file.
+func (cl *Client) collect(kind string, p *Provenance, n int) {
+	cl.logRetrieved(kind, p, n)
+	if cl.metrics == nil {
+		return
+	}
+	cl.metrics.Cache(kind, p.Cached)
+	if !p.Cached {
+		cl.metrics.Downloaded(kind, int64(n))
+	}
+}
+
+// metricsTransport is a http transport that collects upstream request
+// metrics.
+type metricsTransport struct {
+	transport http.RoundTripper
+	metrics   Collector
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.transport.RoundTrip(req)
+	code := 0
+	if err == nil {
+		code = res.StatusCode
+	}
+	t.metrics.Request(req.URL.Hostname(), code, time.Since(start))
+	return res, err
+}
@@ -0,0 +1,71 @@
+package webfonts
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Mirror is a google fonts stylesheet endpoint.
+type Mirror string
+
+// Mirrors.
+const (
+	MirrorGoogle   Mirror = "https://fonts.googleapis.com"
+	MirrorGoogleCN Mirror = "https://fonts.googleapis.cn"
+	MirrorLoli     Mirror = "https://fonts.loli.net"
+	MirrorUSTC     Mirror = "https://fonts.lug.ustc.edu.cn"
+)
+
+// Mirrors returns the built-in mirror presets, in failover order.
+func Mirrors() []Mirror {
+	return []Mirror{
+		MirrorGoogle,
+		MirrorGoogleCN,
+		MirrorLoli,
+		MirrorUSTC,
+	}
+}
+
+// mirrorOrder returns the order to try n endpoints, starting with the last
+// reachable endpoint.
+func (cl *Client) mirrorOrder(n int) []int {
+	if !cl.failover || n < 2 {
+		return []int{0}
+	}
+	start := int(atomic.LoadInt32(&cl.mirror)) % n
+	order := make([]int, n)
+	for i := 0; i < n; i++ {
+		order[i] = (start + i) % n
+	}
+	return order
+}
+
+// setMirror sets the last reachable mirror.
+func (cl *Client) setMirror(i int) {
+	atomic.StoreInt32(&cl.mirror, int32(i))
+}
+
+// unreachableError wraps an error that occurred reaching an endpoint.
+type unreachableError struct {
+	err error
+}
+
+// Error satisfies the error interface.
+func (err *unreachableError) Error() string {
+	return err.err.Error()
+}
+
+// Unwrap satisfies the errors.Unwrap interface.
+func (err *unreachableError) Unwrap() error {
+	return err.err
+}
+
+// unwrapUnreachable unwraps an unreachable error, returning the underlying
+// error and true when the error is an unreachable error.
+func unwrapUnreachable(err error) (error, bool) {
+	var e *unreachableError
+	if errors.As(err, &e) {
+		return e.err, true
+	}
+	return err, false
+}
@@ -0,0 +1,146 @@
+package webfonts
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kenshaw/webfonts/convert"
+)
+
+// WarningKind is a stylesheet parse warning kind.
+type WarningKind string
+
+// Warning kinds.
+const (
+	// WarningSkippedRule is a rule that is not a @font-face or font effect
+	// rule.
+	WarningSkippedRule WarningKind = "skipped rule"
+	// WarningInvalidFontFace is a @font-face rule that could not be parsed.
+	WarningInvalidFontFace WarningKind = "invalid font face"
+	// WarningUnknownDescriptor is an unknown @font-face descriptor (see
+	// Font.Extra).
+	WarningUnknownDescriptor WarningKind = "unknown descriptor"
+	// WarningSubsetMismatch is a @font-face rule whose subset comment (ie,
+	// "/* latin */") names a known subset not overlapping the rule's
+	// unicode-range.
+	WarningSubsetMismatch WarningKind = "subset mismatch"
+)
+
+// Warning is a stylesheet parse warning.
+type Warning struct {
+	Kind WarningKind `json:"kind"`
+	// Rule is the rule's prelude (ie, "@font-face", "@import url(...)").
+	Rule string `json:"rule,omitempty"`
+	// Family is the font family of the @font-face rule, if any.
+	Family string `json:"family,omitempty"`
+	// Detail is the warning detail, such as the descriptor name or parse
+	// error.
+	Detail string `json:"detail,omitempty"`
+}
+
+// String satisfies the fmt.Stringer interface.
+func (w Warning) String() string {
+	s := string(w.Kind) + ": " + w.Rule
+	if w.Family != "" {
+		s += " (" + w.Family + ")"
+	}
+	if w.Detail != "" {
+		s += ": " + w.Detail
+	}
+	return s
+}
+
+// ParseResult is the result of parsing a stylesheet.
+type ParseResult struct {
+	Fonts []Font `json:"fonts"`
+	// Effects are the font effect rules (ie, ".font-effect-shadow-multiple"),
+	// returned when the stylesheet was requested with effects (see
+	// WithEffects). Effects can be included in generated stylesheets (see
+	// WithEffectRules).
+	Effects  []Effect  `json:"effects,omitempty"`
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// ParseStylesheet parses the stylesheet from the passed reader, returning the
+// parsed font faces (see FontsFromStylesheetReader) and font effect rules
+// (see EffectsFromStylesheetReader) along with warnings for skipped rules,
+// unknown descriptors, and subset comment mismatches. Unlike
+// FontsFromStylesheetReader, @font-face rules that cannot be parsed are
+// skipped with a warning. An error is only returned when the stylesheet
+// cannot be read or scanned.
+func ParseStylesheet(r io.Reader) (*ParseResult, error) {
+	s := newCSSScanner(r)
+	res := new(ParseResult)
+	for {
+		rule, err := s.next()
+		switch {
+		case err == io.EOF:
+			return res, nil
+		case err != nil:
+			return nil, err
+		case !strings.EqualFold(rule.prelude, "@font-face") || rule.statement:
+			effect, ok := parseEffect(rule)
+			switch {
+			case ok && !rule.statement:
+				res.Effects = append(res.Effects, effect)
+			case !strings.HasPrefix(strings.ToLower(rule.prelude), "@charset"):
+				res.warn(WarningSkippedRule, rule.prelude, "", "")
+			}
+			continue
+		}
+		font, err := parseFontFace(rule)
+		if err != nil {
+			res.warn(WarningInvalidFontFace, rule.prelude, "", err.Error())
+			continue
+		}
+		// unknown descriptors
+		var names []string
+		for name := range font.Extra {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			res.warn(WarningUnknownDescriptor, rule.prelude, font.Family, name)
+		}
+		// subset comment
+		if detail := subsetMismatch(font); detail != "" {
+			res.warn(WarningSubsetMismatch, rule.prelude, font.Family, detail)
+		}
+		res.Fonts = append(res.Fonts, font)
+	}
+}
+
+// warn adds a warning to the result.
+func (res *ParseResult) warn(kind WarningKind, rule, family, detail string) {
+	res.Warnings = append(res.Warnings, Warning{
+		Kind:   kind,
+		Rule:   rule,
+		Family: family,
+		Detail: detail,
+	})
+}
+
+// subsetMismatch returns a description of the mismatch when the font's subset
+// is a known subset that does not overlap the font's unicode-range.
+func subsetMismatch(font Font) string {
+	if font.Subset == "" || len(font.Range) == 0 {
+		return ""
+	}
+	v, ok := convert.SubsetRanges(font.Subset)
+	if !ok {
+		return ""
+	}
+	subset, err := ParseRuneRanges(v...)
+	if err != nil {
+		return ""
+	}
+	ranges, err := font.RuneRanges()
+	switch {
+	case err != nil:
+		return err.Error()
+	case !ranges.Overlaps(subset):
+		return font.Subset + " does not overlap " + strings.Join(font.Range, ", ")
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package webfonts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultConcurrency is the default number of concurrent requests.
+var DefaultConcurrency = 5
+
+// parallel runs f for each of 0..n-1, using at most concurrency concurrent
+// workers, and returning any errors as a MultiError ordered by i. Returned
+// MultiErrors are flattened.
+func parallel(concurrency, n int, f func(int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	errs := make([]error, n)
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				errs[i] = f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+	var err MultiError
+	for _, e := range errs {
+		switch m, ok := e.(MultiError); {
+		case ok:
+			err = append(err, m...)
+		case e != nil:
+			err = append(err, e)
+		}
+	}
+	if len(err) != 0 {
+		return err
+	}
+	return nil
+}
+
+// share runs f once for concurrent calls with the same key (see
+// singleflight.Group). The shared call runs under a context detached from the
+// caller's cancellation, bounded by timeout (when greater than 0), so that a
+// canceled caller does not fail the other callers sharing the call. Each
+// caller returns when its own context is done.
+func share(ctx context.Context, group *singleflight.Group, key string, timeout time.Duration, f func(context.Context) (interface{}, error)) (interface{}, error) {
+	ch := group.DoChan(key, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return f(ctx)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		return res.Val, res.Err
+	}
+}
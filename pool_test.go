@@ -0,0 +1,64 @@
+package webfonts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+func TestShareCanceledCaller(t *testing.T) {
+	var group singleflight.Group
+	started, release := make(chan struct{}), make(chan struct{})
+	f := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+			return "ok", nil
+		}
+	}
+	// first caller, canceled while the shared call is running
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := share(ctx, &group, "key", 0, f)
+		errc <- err
+	}()
+	<-started
+	// second caller, sharing the call
+	resc := make(chan interface{}, 1)
+	go func() {
+		v, err := share(context.Background(), &group, "key", 0, func(context.Context) (interface{}, error) {
+			return nil, errors.New("not shared")
+		})
+		if err != nil {
+			v = err
+		}
+		resc <- v
+	}()
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got: %v", context.Canceled, err)
+	}
+	// wait for the second caller to join the call before releasing
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if v := <-resc; v != "ok" {
+		t.Errorf("expected ok, got: %v", v)
+	}
+}
+
+func TestShareTimeout(t *testing.T) {
+	var group singleflight.Group
+	_, err := share(context.Background(), &group, "key", time.Millisecond, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got: %v", context.DeadlineExceeded, err)
+	}
+}
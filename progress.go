@@ -0,0 +1,123 @@
+package webfonts
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// ProgressEvent is a progress event for a file retrieved by a bulk operation
+// (see WithProgress).
+type ProgressEvent struct {
+	// Family is the file's family.
+	Family string
+	// Format is the file's font format, if any.
+	Format Format
+	// URL is the file's url.
+	URL string
+	// Bytes is the number of bytes transferred for the file.
+	Bytes int64
+	// Size is the file's size, or -1 when unknown.
+	Size int64
+	// Done is whether or not the file has been transferred.
+	Done bool
+	// Files is the number of files transferred by the operation.
+	Files int
+	// Total is the total number of files to transfer for the operation.
+	Total int
+}
+
+// progressKey is the context key for progress.
+type progressKey struct{}
+
+// progress tracks the progress of a bulk operation.
+type progress struct {
+	f     func(ProgressEvent)
+	total int
+	mu    sync.Mutex
+	files int
+}
+
+// newProgress creates a progress tracker for an operation transferring total
+// files. Returns nil when the client has no progress func.
+func (cl *Client) newProgress(total int) *progress {
+	if cl.progress == nil {
+		return nil
+	}
+	return &progress{
+		f:     cl.progress,
+		total: total,
+	}
+}
+
+// with adds the progress for a file of the family and format to the context.
+func (p *progress) with(parent context.Context, family string, format Format) context.Context {
+	if p == nil {
+		return parent
+	}
+	return context.WithValue(parent, progressKey{}, &progressFile{
+		p:      p,
+		family: family,
+		format: format,
+	})
+}
+
+// progressFile is the progress of a file.
+type progressFile struct {
+	p      *progress
+	family string
+	format Format
+}
+
+// progressBody wraps the response body with a reader reporting progress to
+// the progress in the context, if any.
+func progressBody(ctx context.Context, urlstr string, body io.Reader, size int64) io.Reader {
+	f, ok := ctx.Value(progressKey{}).(*progressFile)
+	if !ok {
+		return body
+	}
+	return &progressReader{
+		r:   body,
+		f:   f,
+		url: urlstr,
+		sz:  size,
+	}
+}
+
+// progressReader is a reader reporting progress.
+type progressReader struct {
+	r    io.Reader
+	f    *progressFile
+	url  string
+	n    int64
+	sz   int64
+	done bool
+}
+
+// Read satisfies the io.Reader interface.
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	if r.done || (n == 0 && err == nil) {
+		return n, err
+	}
+	p := r.f.p
+	p.mu.Lock()
+	if err == io.EOF {
+		r.done = true
+		p.files++
+	}
+	ev := ProgressEvent{
+		Family: r.f.family,
+		Format: r.f.format,
+		URL:    r.url,
+		Bytes:  r.n,
+		Size:   r.sz,
+		Done:   r.done,
+		Files:  p.files,
+		Total:  p.total,
+	}
+	p.mu.Unlock()
+	p.f(ev)
+	return n, err
+}
@@ -0,0 +1,61 @@
+package webfonts
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Provenance describes where a retrieved stylesheet was served from.
+type Provenance struct {
+	// URL is the requested url.
+	URL string `json:"url,omitempty"`
+	// Cached is whether or not the response was served from the disk cache.
+	Cached bool `json:"cached"`
+	// Time is the time the response was retrieved from the network. For cached
+	// responses, this is the time of the original retrieval.
+	Time time.Time `json:"time"`
+	// Revalidated is whether or not a stale cached response was revalidated
+	// with a conditional request (ie, the server responded 304 Not Modified).
+	Revalidated bool `json:"revalidated,omitempty"`
+}
+
+// provenanceKey is the context key for provenance.
+type provenanceKey struct{}
+
+// withProvenance adds a provenance to the context.
+func withProvenance(parent context.Context, p *Provenance) context.Context {
+	return context.WithValue(parent, provenanceKey{}, p)
+}
+
+// networkTransport is a http transport that marks any provenance in the
+// request context as having been retrieved from the network. Used as the
+// underlying transport for the disk cache.
+type networkTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *networkTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := req.Context().Value(provenanceKey{}).(*Provenance); ok {
+		p.Cached, p.Time = false, time.Now()
+	}
+	return res, nil
+}
+
+// buildProvenance builds the provenance for the response. Responses that did
+// not traverse the network transport are marked as cached, using the response
+// Date header as the retrieval time.
+func buildProvenance(p *Provenance, res *http.Response) *Provenance {
+	if !p.Cached {
+		return p
+	}
+	if t, err := http.ParseTime(res.Header.Get("Date")); err == nil {
+		p.Time = t
+	}
+	return p
+}
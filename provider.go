@@ -0,0 +1,183 @@
+package webfonts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Provider is a font provider.
+//
+// Custom providers (such as a google-webfonts-helper instance, or an internal
+// font CDN) can be used with a client by using WithProvider.
+type Provider interface {
+	// List lists the available font families.
+	List(ctx context.Context, cl *Client, opts *ListOptions) ([]Family, error)
+	// Stylesheet returns the stylesheet urls for the query, in failover
+	// order. The client uses the first url, unless failover is enabled.
+	Stylesheet(cl *Client, q *Query) []string
+	// Resolve resolves a src url contained in the stylesheet retrieved from
+	// the base url to an absolute url.
+	Resolve(base, src string) (string, error)
+}
+
+// Providers.
+var (
+	// Google is the google fonts provider.
+	Google Provider = googleProvider{}
+	// Bunny is the bunny fonts provider.
+	Bunny Provider = bunnyProvider{
+		endpoint: "https://fonts.bunny.net",
+	}
+)
+
+// googleProvider is the google fonts provider.
+type googleProvider struct{}
+
+// List satisfies the Provider interface. Lists the available font families
+// using the client's service (see WithService).
+func (googleProvider) List(ctx context.Context, cl *Client, o *ListOptions) ([]Family, error) {
+	if cl.service == nil {
+		return nil, ErrServiceUninitialized
+	}
+	return cl.service.List(ctx, o)
+}
+
+// Stylesheet satisfies the Provider interface. Returns a url for each of the
+// client's mirrors.
+func (googleProvider) Stylesheet(cl *Client, q *Query) []string {
+	v := make([]string, len(cl.mirrors))
+	for i, mirror := range cl.mirrors {
+		v[i] = q.URL(string(mirror))
+	}
+	return v
+}
+
+// Resolve satisfies the Provider interface.
+func (googleProvider) Resolve(base, src string) (string, error) {
+	return resolveURL(base, src)
+}
+
+// bunnyProvider is the bunny fonts provider.
+type bunnyProvider struct {
+	endpoint string
+}
+
+// List satisfies the Provider interface.
+func (p bunnyProvider) List(ctx context.Context, cl *Client, o *ListOptions) ([]Family, error) {
+	hc, err := cl.HTTPClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// retrieve
+	urlstr := p.endpoint + "/list"
+	req, err := http.NewRequest("GET", urlstr, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, newStatusError(urlstr, res)
+	}
+	var m map[string]bunnyFamily
+	if err := json.NewDecoder(res.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", urlstr, err)
+	}
+	// convert and filter
+	var families []Family
+	for _, f := range m {
+		family := f.family()
+		switch {
+		case len(o.Families) != 0 && !contains(o.Families, family.Name),
+			o.Subset != "" && !contains(family.Subsets, o.Subset):
+			continue
+		}
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families, nil
+}
+
+// Stylesheet satisfies the Provider interface.
+func (p bunnyProvider) Stylesheet(_ *Client, q *Query) []string {
+	return []string{p.endpoint + "/css?" + q.Values().Encode()}
+}
+
+// Resolve satisfies the Provider interface.
+func (bunnyProvider) Resolve(base, src string) (string, error) {
+	return resolveURL(base, src)
+}
+
+// resolveURL resolves src relative to base.
+func resolveURL(base, src string) (string, error) {
+	u, err := url.Parse(src)
+	switch {
+	case err != nil:
+		return "", fmt.Errorf("invalid src url %q: %w", src, err)
+	case u.IsAbs():
+		return src, nil
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %q: %w", base, err)
+	}
+	return b.ResolveReference(u).String(), nil
+}
+
+// bunnyFamily is a bunny fonts list entry.
+type bunnyFamily struct {
+	FamilyName string   `json:"familyName"`
+	Category   string   `json:"category"`
+	DefSubset  string   `json:"defSubset"`
+	Subsets    []string `json:"subsets"`
+	Styles     []string `json:"styles"`
+	Weights    []int    `json:"weights"`
+	IsVariable bool     `json:"isVariable"`
+}
+
+// family converts the bunny fonts list entry to a family.
+func (f bunnyFamily) family() Family {
+	family := Family{
+		Name:     f.FamilyName,
+		Category: f.Category,
+		Subsets:  f.Subsets,
+	}
+	if len(family.Subsets) == 0 && f.DefSubset != "" {
+		family.Subsets = []string{f.DefSubset}
+	}
+	weights := append([]int(nil), f.Weights...)
+	sort.Ints(weights)
+	for _, style := range []string{"normal", "italic"} {
+		if !contains(f.Styles, style) {
+			continue
+		}
+		for _, weight := range weights {
+			family.Variants = append(family.Variants, variantName(strconv.Itoa(weight), style))
+		}
+	}
+	return family
+}
+
+// variantName returns the google variant name for the weight and style (ie,
+// "regular", "italic", "700", "700italic").
+func variantName(weight, style string) string {
+	switch {
+	case weight == "400" && style == "italic":
+		return "italic"
+	case weight == "400":
+		return "regular"
+	case style == "italic":
+		return weight + "italic"
+	}
+	return weight
+}
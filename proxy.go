@@ -0,0 +1,123 @@
+package webfonts
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Proxy is a http handler that proxies Google Fonts stylesheets and font
+// files, allowing pages to keep their stylesheet <link> tags (ie,
+// <link href="/fonts/css2?family=Roboto">) without browsers contacting
+// Google.
+//
+// Stylesheets are served as <prefix>css and <prefix>css2, with the font file
+// urls in the stylesheet rewritten to <prefix><path>. Stylesheets and font
+// files are retrieved using the client, and are cached by the client's caches
+// (see WithAppCacheDir, WithFontCacheDir, WithMemoryCache). Stylesheets are
+// retrieved using the requesting browser's user agent, so that the font
+// formats supported by the browser are served.
+//
+// Requests not under the prefix are passed to the next handler.
+type Proxy struct {
+	cl     *Client
+	prefix string
+	next   http.Handler
+}
+
+// NewProxy creates a new Google Fonts proxy for requests under the prefix,
+// passing other requests to next (when nil, other requests are not found).
+func NewProxy(prefix string, next http.Handler, opts ...ClientOption) *Proxy {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &Proxy{
+		cl:     NewClient(opts...),
+		prefix: prefix,
+		next:   next,
+	}
+}
+
+// ProxyMiddleware returns http middleware that proxies Google Fonts
+// stylesheets and font files under the prefix (see NewProxy).
+func ProxyMiddleware(prefix string, opts ...ClientOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return NewProxy(prefix, next, opts...)
+	}
+}
+
+// ServeHTTP satisfies the http.Handler interface.
+func (p *Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(path.Clean(req.URL.Path), p.prefix)
+	switch {
+	case !strings.HasPrefix(req.URL.Path, p.prefix) || name == "" || strings.HasPrefix(name, "/"):
+		if p.next == nil {
+			http.NotFound(res, req)
+			return
+		}
+		p.next.ServeHTTP(res, req)
+		return
+	case req.Method != http.MethodGet && req.Method != http.MethodHead:
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	// initialize
+	ctx := req.Context()
+	if err := p.cl.init(ctx); err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var buf []byte
+	var contentType string
+	var err error
+	switch name {
+	case "css", "css2":
+		// retrieve and rewrite stylesheet
+		userAgent := req.UserAgent()
+		if userAgent == "" {
+			userAgent = p.cl.buildUserAgent(ctx)
+		}
+		urlstr := string(MirrorGoogle) + "/" + name + "?" + req.URL.RawQuery
+		if buf, _, err = p.cl.fetch(ctx, urlstr, userAgent); err == nil {
+			buf, _, err = RewriteStylesheet(buf, p.rewrite)
+		}
+		contentType = "text/css; charset=utf-8"
+		res.Header().Set("Vary", "User-Agent")
+	default:
+		// retrieve font file
+		urlstr := gstaticURL + "/" + name
+		if req.URL.RawQuery != "" {
+			urlstr += "?" + req.URL.RawQuery
+		}
+		buf, err = p.cl.download(ctx, urlstr)
+		contentType = formatName(path.Ext(name)).ContentType()
+	}
+	if err != nil {
+		code := http.StatusBadGateway
+		var se *StatusError
+		if errors.As(err, &se) && se.Code < http.StatusInternalServerError {
+			code = se.Code
+		}
+		http.Error(res, http.StatusText(code), code)
+		return
+	}
+	res.Header().Set("Content-Type", contentType)
+	_, _ = res.Write(buf)
+}
+
+// rewrite rewrites font file urls to the proxy's prefix.
+func (p *Proxy) rewrite(urlstr string) string {
+	u, err := url.Parse(urlstr)
+	if err != nil || !strings.EqualFold(u.Host, "fonts.gstatic.com") {
+		return ""
+	}
+	if u.RawQuery != "" {
+		return p.prefix + strings.TrimPrefix(u.Path, "/") + "?" + u.RawQuery
+	}
+	return p.prefix + strings.TrimPrefix(u.Path, "/")
+}
+
+// gstaticURL is the google fonts font file url.
+const gstaticURL = "https://fonts.gstatic.com"
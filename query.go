@@ -0,0 +1,183 @@
+package webfonts
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kenshaw/webfonts/convert"
+)
+
+// ParseQuery parses a css or css2 api stylesheet url (as copied from the
+// Google Fonts embed code, ie,
+// https://fonts.googleapis.com/css2?family=Roboto:wght@400;700&display=swap)
+// into a query. Html escaped urls (&amp;) are accepted.
+//
+// A query's variants and axes apply to all of its families, so the variants
+// and axis values of each family in the url are merged. Axis values are
+// retrieved for all combinations of the parsed axis values.
+func ParseQuery(urlstr string) (*Query, error) {
+	u, err := url.Parse(strings.TrimSpace(strings.ReplaceAll(urlstr, "&amp;", "&")))
+	if err != nil {
+		return nil, err
+	}
+	css2 := false
+	switch path.Base(u.Path) {
+	case "css":
+	case "css2":
+		css2 = true
+	default:
+		return nil, fmt.Errorf("%s: %w", urlstr, ErrInvalidQueryURL)
+	}
+	// parse values, allowing the ; separators used by the css2 api
+	v := make(url.Values)
+	for _, s := range strings.Split(u.RawQuery, "&") {
+		key, value, _ := strings.Cut(s, "=")
+		if key, err = url.QueryUnescape(key); err != nil {
+			return nil, err
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, err
+		}
+		if key != "" {
+			v[key] = append(v[key], value)
+		}
+	}
+	// parse families
+	q := new(Query)
+	for _, spec := range v["family"] {
+		if css2 {
+			if err := q.addCSS2Family(spec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, s := range strings.Split(spec, "|") {
+			q.addCSSFamily(s)
+		}
+	}
+	switch len(q.Families) {
+	case 0:
+		return nil, fmt.Errorf("%s: %w", urlstr, ErrInvalidQueryURL)
+	case 1:
+		q.Family, q.Families = q.Families[0], nil
+	}
+	// parse options
+	if s := v.Get("subset"); s != "" {
+		q.Subsets = splitNonEmpty(s, ",")
+	}
+	if s := v.Get("effect"); s != "" {
+		q.Effects = splitNonEmpty(s, "|")
+	}
+	q.Directory, q.Display, q.Text = v.Get("directory"), v.Get("display"), v.Get("text")
+	return q, nil
+}
+
+// addCSSFamily adds a css api family specification (ie,
+// "Roboto:400,700italic") to the query.
+func (q *Query) addCSSFamily(spec string) {
+	family, variants, _ := strings.Cut(spec, ":")
+	if family = strings.TrimSpace(family); family == "" {
+		return
+	}
+	q.Families = append(q.Families, family)
+	for _, variant := range splitNonEmpty(variants, ",") {
+		if !contains(q.Variants, variant) {
+			q.Variants = append(q.Variants, variant)
+		}
+	}
+}
+
+// addCSS2Family adds a css2 api family specification (ie,
+// "Roboto:ital,wght@0,400;1,700") to the query.
+func (q *Query) addCSS2Family(spec string) error {
+	family, axes, _ := strings.Cut(spec, ":")
+	if family = strings.TrimSpace(family); family == "" {
+		return nil
+	}
+	q.Families = append(q.Families, family)
+	if axes == "" {
+		return nil
+	}
+	tags, tuples, ok := strings.Cut(axes, "@")
+	if !ok {
+		return fmt.Errorf("invalid axes %q: %w", axes, ErrInvalidQueryURL)
+	}
+	names := strings.Split(tags, ",")
+	if q.Axes == nil {
+		q.Axes = make(map[string][]string)
+	}
+	for _, tuple := range strings.Split(tuples, ";") {
+		values := strings.Split(tuple, ",")
+		if len(values) != len(names) {
+			return fmt.Errorf("invalid axes %q: %w", axes, ErrInvalidQueryURL)
+		}
+		for i, name := range names {
+			if !contains(q.Axes[name], values[i]) {
+				q.Axes[name] = append(q.Axes[name], values[i])
+			}
+		}
+	}
+	for _, values := range q.Axes {
+		sort.SliceStable(values, func(i, j int) bool {
+			return axisValueStart(values[i]) < axisValueStart(values[j])
+		})
+	}
+	return nil
+}
+
+// splitNonEmpty splits s by sep, omitting empty values.
+func splitNonEmpty(s, sep string) []string {
+	var v []string
+	for _, z := range strings.Split(s, sep) {
+		if z = strings.TrimSpace(z); z != "" {
+			v = append(v, z)
+		}
+	}
+	return v
+}
+
+// MaxTextRunes is the maximum number of distinct runes for which
+// OptimizeForText sets a query's text.
+const MaxTextRunes = 64
+
+// OptimizeForText sets the query's text or subsets to minimize the font data
+// served for rendering the text, such as for headlines and logos.
+//
+// When the text has at most MaxTextRunes distinct runes, or has runes not in
+// a known subset, the query's text is set to the text's sorted distinct runes
+// and the query's subsets are cleared. Otherwise, the query's subsets are set
+// to the named subsets needed for the text (see convert.TextSubsets).
+func OptimizeForText(q *Query, text string) {
+	subsets, missing := convert.TextSubsets(text)
+	runes := make(map[rune]bool)
+	for _, r := range text {
+		runes[r] = true
+	}
+	if len(runes) == 0 {
+		return
+	}
+	if len(runes) <= MaxTextRunes || len(missing) != 0 {
+		v := make([]rune, 0, len(runes))
+		for r := range runes {
+			v = append(v, r)
+		}
+		sort.Slice(v, func(i, j int) bool {
+			return v[i] < v[j]
+		})
+		q.Text, q.Subsets = string(v), nil
+		return
+	}
+	q.Text, q.Subsets = "", subsets
+}
+
+// WithOptimizeText is a query option to optimize the query for rendering the
+// text (see OptimizeForText). Should be passed after any other subset or text
+// query options.
+func WithOptimizeText(text string) QueryOption {
+	return func(q *Query) {
+		OptimizeForText(q, text)
+	}
+}
@@ -0,0 +1,80 @@
+package webfonts
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport is a http transport that limits the rate of requests.
+type rateLimitTransport struct {
+	transport http.RoundTripper
+	limiter   *limiter
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// limiter is a token bucket rate limiter.
+type limiter struct {
+	rate   float64
+	burst  float64
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter creates a token bucket rate limiter allowing rps events per
+// second, with bursts of at most burst events.
+func newLimiter(rps float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait waits until an event is allowed, or the context is done.
+func (l *limiter) wait(ctx context.Context) error {
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token when available, returning 0, otherwise returns the
+// duration until a token is available.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
@@ -0,0 +1,90 @@
+package webfonts
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// recordTransport is a http transport that records responses to golden files
+// in a directory (see WithRecord).
+type recordTransport struct {
+	dir       string
+	transport http.RoundTripper
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := httputil.DumpResponse(res, true)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	name := recordPath(t.dir, req)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0o644); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+// replayTransport is a http transport that replays responses from golden
+// files in a directory (see WithReplay).
+type replayTransport struct {
+	dir string
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	buf, err := ioutil.ReadFile(recordPath(t.dir, req))
+	switch {
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf("%s %s: %w", req.Method, redactURL(req.URL), ErrNotRecorded)
+	case err != nil:
+		return nil, err
+	}
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(buf)), req)
+}
+
+// recordPath returns the golden file path for the request, in the form of
+// <dir>/<host>/<hash>.http. The hash is of the request's method, url
+// (without any api key), and user agent.
+func recordPath(dir string, req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + "\n" + redactURL(req.URL) + "\n" + req.UserAgent()))
+	host := strings.NewReplacer(":", "_").Replace(req.URL.Host)
+	return filepath.Join(dir, host, hex.EncodeToString(sum[:16])+".http")
+}
+
+// redactURL returns the url without its api key.
+func redactURL(u *url.URL) string {
+	if !strings.Contains(u.RawQuery, "key=") {
+		return u.String()
+	}
+	v := u.Query()
+	v.Del("key")
+	z := *u
+	z.RawQuery = v.Encode()
+	return z.String()
+}
@@ -0,0 +1,111 @@
+package webfonts
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Backoff is a retry backoff policy, returning the duration to wait before the
+// retry attempt (starting at 1).
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff returns a backoff policy that waits a random duration
+// between 0 and min(max, base*2^(attempt-1)) (ie, exponential backoff with
+// full jitter).
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		if d <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	}
+}
+
+// DefaultBackoff is the default retry backoff policy.
+var DefaultBackoff = ExponentialBackoff(250*time.Millisecond, 10*time.Second)
+
+// retryTransport is a http transport that retries transient failures (429 and
+// 5xx responses, and transport errors).
+type retryTransport struct {
+	transport http.RoundTripper
+	retries   int
+	backoff   Backoff
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := t.transport.RoundTrip(req)
+		if attempt >= t.retries || !retryable(req, res, err) {
+			return res, err
+		}
+		// determine wait
+		d := t.backoff(attempt + 1)
+		if res != nil {
+			if v, ok := retryAfter(res); ok {
+				d = v
+			}
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64*1024))
+			res.Body.Close()
+		}
+		// wait
+		timer := time.NewTimer(d)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		// rewind body
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable returns true when the request can be retried.
+func retryable(req *http.Request, res *http.Response, err error) bool {
+	switch {
+	case req.Context().Err() != nil:
+		return false
+	case req.Body != nil && req.GetBody == nil:
+		return false
+	case err != nil:
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+}
+
+// retryAfter returns the Retry-After duration for the response.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,231 @@
+package webfonts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			if d := backoff(test.attempt); d < 0 || d > test.max {
+				t.Fatalf("attempt %d: expected 0 <= d <= %v, got: %v", test.attempt, test.max, d)
+			}
+		}
+	}
+	if d := ExponentialBackoff(0, time.Second)(3); d != 0 {
+		t.Errorf("expected 0, got: %v", d)
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		codes    []int
+		retries  int
+		exp      int
+		attempts int
+	}{
+		{"ok", []int{200}, 3, 200, 1},
+		{"server error", []int{503, 500, 200}, 3, 200, 3},
+		{"too many requests", []int{429, 200}, 3, 200, 2},
+		{"exhausted", []int{503, 503, 503}, 2, 503, 3},
+		{"no retries", []int{503, 200}, 0, 503, 1},
+		{"not found", []int{404, 200}, 3, 404, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rt := &fakeRoundTripper{codes: test.codes}
+			transport := &retryTransport{
+				transport: rt,
+				retries:   test.retries,
+				backoff:   noBackoff,
+			}
+			req, _ := http.NewRequest("GET", "https://example.com/", nil)
+			res, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != test.exp {
+				t.Errorf("expected %d, got: %d", test.exp, res.StatusCode)
+			}
+			if rt.attempts != test.attempts {
+				t.Errorf("expected %d attempts, got: %d", test.attempts, rt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportError(t *testing.T) {
+	errTransport := errors.New("connection reset")
+	rt := &fakeRoundTripper{errs: []error{errTransport, nil}, codes: []int{0, 200}}
+	transport := &retryTransport{transport: rt, retries: 1, backoff: noBackoff}
+	req, _ := http.NewRequest("GET", "https://example.com/", nil)
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	res.Body.Close()
+	if rt.attempts != 2 {
+		t.Errorf("expected 2 attempts, got: %d", rt.attempts)
+	}
+	rt = &fakeRoundTripper{errs: []error{errTransport, errTransport}, codes: []int{0, 0}}
+	transport = &retryTransport{transport: rt, retries: 1, backoff: noBackoff}
+	if _, err := transport.RoundTrip(req); err != errTransport {
+		t.Errorf("expected %v, got: %v", errTransport, err)
+	}
+}
+
+func TestRetryTransportBody(t *testing.T) {
+	// rewindable body
+	rt := &fakeRoundTripper{codes: []int{503, 200}}
+	transport := &retryTransport{transport: rt, retries: 1, backoff: noBackoff}
+	req, _ := http.NewRequest("POST", "https://example.com/", strings.NewReader("body"))
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	res.Body.Close()
+	if exp := []string{"body", "body"}; strings.Join(rt.bodies, ",") != strings.Join(exp, ",") {
+		t.Errorf("expected %q, got: %q", exp, rt.bodies)
+	}
+	// body that cannot be rewound
+	rt = &fakeRoundTripper{codes: []int{503, 200}}
+	transport = &retryTransport{transport: rt, retries: 1, backoff: noBackoff}
+	req, _ = http.NewRequest("POST", "https://example.com/", ioutil.NopCloser(strings.NewReader("body")))
+	res, err = transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 503 || rt.attempts != 1 {
+		t.Errorf("expected a single 503 attempt, got: %d (%d attempts)", res.StatusCode, rt.attempts)
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	rt := &fakeRoundTripper{codes: []int{429, 200}, retryAfter: "0"}
+	transport := &retryTransport{
+		transport: rt,
+		retries:   1,
+		backoff: func(int) time.Duration {
+			return time.Hour
+		},
+	}
+	req, _ := http.NewRequest("GET", "https://example.com/", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != 200 {
+		t.Errorf("expected 200, got: %d", res.StatusCode)
+	}
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	rt := &fakeRoundTripper{codes: []int{503, 200}}
+	transport := &retryTransport{
+		transport: rt,
+		retries:   1,
+		backoff: func(int) time.Duration {
+			return time.Hour
+		},
+	}
+	req, _ := http.NewRequest("GET", "https://example.com/", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got: %v", context.DeadlineExceeded, err)
+	}
+	if rt.attempts != 1 {
+		t.Errorf("expected 1 attempt, got: %d", rt.attempts)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		v   string
+		exp time.Duration
+		ok  bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, test := range tests {
+		res := &http.Response{Header: http.Header{}}
+		if test.v != "" {
+			res.Header.Set("Retry-After", test.v)
+		}
+		d, ok := retryAfter(res)
+		if d != test.exp || ok != test.ok {
+			t.Errorf("%q: expected %v %t, got: %v %t", test.v, test.exp, test.ok, d, ok)
+		}
+	}
+	res := &http.Response{Header: http.Header{"Retry-After": []string{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}}
+	if d, ok := retryAfter(res); !ok || d <= 0 || d > time.Hour {
+		t.Errorf("expected 0 < d <= 1h, got: %v %t", d, ok)
+	}
+}
+
+// noBackoff is a backoff policy that does not wait.
+func noBackoff(int) time.Duration {
+	return 0
+}
+
+// fakeRoundTripper is a round tripper returning the status codes (or errors)
+// in order.
+type fakeRoundTripper struct {
+	codes      []int
+	errs       []error
+	retryAfter string
+	attempts   int
+	bodies     []string
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (rt *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := rt.attempts
+	rt.attempts++
+	if req.Body != nil {
+		buf, _ := io.ReadAll(req.Body)
+		rt.bodies = append(rt.bodies, string(buf))
+	}
+	if i < len(rt.errs) && rt.errs[i] != nil {
+		return nil, rt.errs[i]
+	}
+	res := &http.Response{
+		StatusCode: rt.codes[i],
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}
+	if rt.retryAfter != "" {
+		res.Header.Set("Retry-After", rt.retryAfter)
+	}
+	return res, nil
+}
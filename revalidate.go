@@ -0,0 +1,62 @@
+package webfonts
+
+import (
+	"net/http"
+
+	"github.com/kenshaw/diskcache"
+)
+
+// revalidateTransport is a http transport that makes conditional requests for
+// stale responses in the disk cache. Used as the underlying transport for the
+// disk cache.
+//
+// When the stale cached response has an ETag or Last-Modified header, the
+// request is made with If-None-Match or If-Modified-Since headers, and a 304
+// Not Modified response is replaced with the stale cached response, which is
+// then stored again by the disk cache.
+type revalidateTransport struct {
+	transport http.RoundTripper
+	cache     *diskcache.Cache
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *revalidateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "GET" || t.cache == nil {
+		return t.transport.RoundTrip(req)
+	}
+	// load stale response
+	key, p, err := t.cache.Match(req)
+	if err != nil || key == "" {
+		return t.transport.RoundTrip(req)
+	}
+	cached, err := t.cache.Load(key, p, req)
+	if err != nil {
+		return t.transport.RoundTrip(req)
+	}
+	etag, lastModified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+	if cached.StatusCode != http.StatusOK || (etag == "" && lastModified == "") {
+		cached.Body.Close()
+		return t.transport.RoundTrip(req)
+	}
+	// revalidate
+	r := req.Clone(req.Context())
+	if etag != "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		r.Header.Set("If-Modified-Since", lastModified)
+	}
+	res, err := t.transport.RoundTrip(r)
+	if err != nil || res.StatusCode != http.StatusNotModified {
+		cached.Body.Close()
+		return res, err
+	}
+	res.Body.Close()
+	if p, ok := req.Context().Value(provenanceKey{}).(*Provenance); ok {
+		p.Revalidated = true
+	}
+	if date := res.Header.Get("Date"); date != "" {
+		cached.Header.Set("Date", date)
+	}
+	return cached, nil
+}
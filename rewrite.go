@@ -0,0 +1,42 @@
+package webfonts
+
+import (
+	"regexp"
+)
+
+// RewriteStylesheet rewrites the url(...) references in the stylesheet using
+// the rewrite func, returning the rewritten stylesheet and a route for each
+// rewritten url, mapping the rewritten path to the original url.
+//
+// When rewrite returns an empty string or the original url, the reference is
+// left unchanged. data: urls are never rewritten.
+func RewriteStylesheet(css []byte, rewrite func(string) string) ([]byte, []Route, error) {
+	var routes []Route
+	seen := make(map[string]bool)
+	buf := urlRE.ReplaceAllFunc(css, func(b []byte) []byte {
+		m := urlRE.FindSubmatch(b)
+		quote, urlstr := string(m[1]), string(m[2])
+		if dataRE.MatchString(urlstr) {
+			return b
+		}
+		path := rewrite(urlstr)
+		if path == "" || path == urlstr {
+			return b
+		}
+		if !seen[urlstr] {
+			seen[urlstr] = true
+			routes = append(routes, Route{
+				Path: path,
+				URL:  urlstr,
+			})
+		}
+		return []byte("url(" + quote + path + quote + ")")
+	})
+	return buf, routes, nil
+}
+
+// urlRE matches url(...) references.
+var urlRE = regexp.MustCompile(`url\(\s*(['"]?)([^'")]+)(?:['"]?)\s*\)`)
+
+// dataRE matches data urls.
+var dataRE = regexp.MustCompile(`(?i)^data:`)
@@ -4,59 +4,50 @@ import (
 	"bytes"
 	"crypto/md5"
 	_ "embed"
+	"encoding/base64"
 	"fmt"
 	"io"
-	"sort"
+	"regexp"
 	"strings"
 	"text/template"
 )
 
 // BuildRoutes builds routes for the provided font faces.
-func BuildRoutes(prefix string, fonts []Font, h func(string, []byte, []Route) error) error {
-	families := make(map[string]map[string]map[string][]Font)
-	// arrange by family, style, weight
-	for _, font := range fonts {
-		if _, ok := families[font.Family]; !ok {
-			families[font.Family] = make(map[string]map[string][]Font)
-		}
-		if _, ok := families[font.Family][font.Style]; !ok {
-			families[font.Family][font.Style] = make(map[string][]Font)
-		}
-		families[font.Family][font.Style][font.Weight] = append(families[font.Family][font.Style][font.Weight], font)
-	}
-	// sort families
-	var familyKeys []string
-	for k := range families {
-		familyKeys = append(familyKeys, k)
-	}
-	sort.Strings(familyKeys)
-	// iterate over families
-	for _, family := range familyKeys {
-		// sort styles
-		var styleKeys []string
-		for k := range families[family] {
-			styleKeys = append(styleKeys, k)
+//
+// The handler is called once for each family, in family name order, with the
+// family's generated stylesheet and routes. Stylesheet @font-face rules (and
+// routes) are generated in a deterministic order: by numeric weight, then
+// style (normal, italic, oblique), and then subset in the order first seen in
+// fonts (see GroupFonts), so that generated stylesheets diff stably.
+func BuildRoutes(prefix string, fonts []Font, h func(string, []byte, []Route) error, opts ...RouteOption) error {
+	o := newRouteOptions(opts...)
+	// filter formats
+	if o.formats != nil {
+		var v []Font
+		for _, font := range fonts {
+			if containsFormat(o.formats, font.Format) {
+				v = append(v, font)
+			}
 		}
-		sort.Strings(styleKeys)
+		fonts = v
+	}
+	// iterate over families, grouped by family, style, weight
+	groups := GroupFonts(fonts)
+	for i := 0; i < len(groups); {
+		family := groups[i].Family
 		buf := new(bytes.Buffer)
 		var routes []Route
-		// iterate over styles
-		for _, style := range styleKeys {
-			// sort weights
-			var weightKeys []string
-			for k := range families[family][style] {
-				weightKeys = append(weightKeys, k)
-			}
-			sort.Strings(weightKeys)
-			// iterate over weights
-			for _, weight := range weightKeys {
-				// process
-				r, err := process(buf, prefix, family, style, weight, families)
-				if err != nil {
-					return err
-				}
-				routes = append(routes, r...)
+		for ; i < len(groups) && groups[i].Family == family; i++ {
+			// process
+			r, err := process(buf, prefix, groups[i], o)
+			if err != nil {
+				return err
 			}
+			routes = append(routes, r...)
+		}
+		// effects
+		for _, effect := range o.effects {
+			fmt.Fprintf(buf, "%s\n", effect.CSS)
 		}
 		// send to handler
 		if err := h(family, buf.Bytes(), routes); err != nil {
@@ -71,63 +62,395 @@ func BuildRoutes(prefix string, fonts []Font, h func(string, []byte, []Route) er
 type Route struct {
 	Path string
 	URL  string
+	// Family is the font file's family.
+	Family string
+	// Style is the font file's style.
+	Style string
+	// Weight is the font file's weight.
+	Weight string
+	// Subset is the font file's subset, if any.
+	Subset string
+	// Format is the font file's format.
+	Format Format
+	// UnicodeRange are the font file's unicode-range values, if any.
+	UnicodeRange []string
+	// Axes are the variable font axes requested for the font file, keyed by
+	// axis tag (ie, "wght"), with either single values (ie, "400") or ranges
+	// (ie, "100..900"), if any (see WithAxes).
+	Axes map[string][]string
+	// ByteSize is the size of the font file, when retrieved (see
+	// WithInline).
+	ByteSize int64
+	// Integrity is the subresource integrity value of the font file, when
+	// computed (see WithIntegrity).
+	Integrity string
+}
+
+// RouteOption is a route option.
+type RouteOption func(*routeOptions)
+
+// routeOptions are route options.
+type routeOptions struct {
+	effects    []Effect
+	inline     func(string) ([]byte, error)
+	threshold  int64
+	naming     func(Font) string
+	local      func(Font) []string
+	tpl        *template.Template
+	formats    []Format
+	variations bool
+}
+
+// newRouteOptions builds route options.
+func newRouteOptions(opts ...RouteOption) *routeOptions {
+	o := &routeOptions{
+		tpl:    tpl,
+		naming: HashName,
+		local: func(Font) []string {
+			return []string{""}
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithEffectRules is a route option to include font effect rules in the
+// generated stylesheets.
+func WithEffectRules(effects ...Effect) RouteOption {
+	return func(o *routeOptions) {
+		o.effects = append(o.effects, effects...)
+	}
+}
+
+// WithRouteNaming is a route option to set the func used to name each font
+// file's route path (default: HashName). Names must be unique for each font
+// file's src url (see VerboseName).
+func WithRouteNaming(naming func(Font) string) RouteOption {
+	return func(o *routeOptions) {
+		o.naming = naming
+	}
+}
+
+// WithLocal is a route option to set the local() sources emitted in the
+// generated stylesheets' src descriptors. When no names are specified, the
+// font's local sources (see Font.Sources) are used.
+//
+// By default, an empty local() source is emitted.
+func WithLocal(names ...string) RouteOption {
+	return func(o *routeOptions) {
+		o.local = func(font Font) []string {
+			if len(names) != 0 {
+				return names
+			}
+			var v []string
+			for _, source := range font.Sources {
+				if source.Local != "" {
+					v = append(v, source.Local)
+				}
+			}
+			return v
+		}
+	}
+}
+
+// WithoutLocal is a route option to not emit any local() sources in the
+// generated stylesheets' src descriptors.
+func WithoutLocal() RouteOption {
+	return func(o *routeOptions) {
+		o.local = func(Font) []string {
+			return nil
+		}
+	}
+}
+
+// WithStylesheetTemplate is a route option to set the template used to
+// generate the @font-face rule for each family, style, and weight
+// combination. The template is executed with a map containing the family,
+// style, weight, display, stretch, ascentOverride, descentOverride,
+// lineGapOverride, sizeAdjust, and unicodeRange descriptor values, the
+// numeric weight range (weightMin and weightMax, equal for static font
+// faces), the oblique angle range in degrees (slantMin and slantMax, zero
+// when not oblique), the tech() src hint (tech, the font faces' parsed tech()
+// hints, or "variations", see WithModernStylesheet), the local() source names
+// (locals), and the font file paths keyed by Format (paths). Templates can use
+// the stylesheet funcs (see StylesheetFuncs).
+func WithStylesheetTemplate(t *template.Template) RouteOption {
+	return func(o *routeOptions) {
+		o.tpl = t
+	}
+}
+
+// WithModernStylesheet is a route option to generate minimal stylesheets for
+// modern browsers, containing only woff2 font files, without local() sources
+// or legacy eot and svg src descriptors. Generated @font-face rules include
+// the font-display (default: swap) and unicode-range descriptors, and the
+// font faces' parsed tech() src hints. When variations is true, variable font
+// files (font faces with a weight range, oblique angle range, or variable
+// axes) without a tech() src hint are marked with the tech(variations) src
+// hint.
+func WithModernStylesheet(variations bool) RouteOption {
+	return func(o *routeOptions) {
+		o.tpl, o.formats, o.variations = modernTpl, []Format{FormatWOFF2}, variations
+		o.local = func(Font) []string {
+			return nil
+		}
+	}
+}
+
+// WithInline is a route option to inline font files in the generated
+// stylesheets as base64 encoded data: urls, using fetch to retrieve each
+// font file's data. Only font files with a size less than or equal to the
+// threshold are inlined (a threshold <= 0 inlines all font files). Inlined
+// font files are not included in the built routes.
+//
+// Only woff2, woff, ttf, and otf font files are inlined.
+func WithInline(threshold int64, fetch func(urlstr string) ([]byte, error)) RouteOption {
+	return func(o *routeOptions) {
+		o.inline, o.threshold = fetch, threshold
+	}
 }
 
 // process generates the stylesheet and routes for the font family, style, and
-// weight combination found in families.
-func process(w io.Writer, prefix, family, style, weight string, families map[string]map[string]map[string][]Font) ([]Route, error) {
+// weight group. A separate @font-face rule is generated for each subset.
+func process(w io.Writer, prefix string, g FaceGroup, o *routeOptions) ([]Route, error) {
+	var routes []Route
+	seen := make(map[string]string)
+	for _, s := range g.Subsets {
+		r, err := processGroup(w, prefix, g.Family, g.Style, g.Weight, s.Fonts, seen, o)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, r...)
+	}
+	return routes, nil
+}
+
+// processGroup generates a @font-face rule and routes for the font files,
+// skipping routes for font files in seen (a map of src urls to paths).
+func processGroup(w io.Writer, prefix, family, style, weight string, fonts []Font, seen map[string]string, o *routeOptions) ([]Route, error) {
 	// build file routes and paths
 	var routes []Route
-	var display string
-	var stretch string
-	paths := make(map[string]string)
-	for _, font := range families[family][style][weight] {
-		if _, ok := paths[font.Format]; !ok {
-			hash := fmt.Sprintf("%x", md5.Sum([]byte(font.Src)))[:7]
-			path := hash + "." + font.Format
-			paths[font.Format] = prefix + path
-			if font.Display != "" && display == "" {
-				display = font.Display
+	var display, stretch string
+	var ascentOverride, descentOverride, lineGapOverride, sizeAdjust string
+	var unicodeRange, tech string
+	var locals []string
+	paths := make(map[Format]string)
+	var variations bool
+	for _, font := range fonts {
+		first(&unicodeRange, strings.Join(font.Range, ", "))
+		first(&tech, strings.Join(font.Tech, ", "))
+		variations = variations || (o.variations && variable(font))
+		for _, name := range o.local(font) {
+			if !contains(locals, name) {
+				locals = append(locals, name)
+			}
+		}
+		if _, ok := paths[font.Format]; ok {
+			continue
+		}
+		first(&display, font.Display)
+		first(&stretch, font.Stretch)
+		first(&ascentOverride, font.AscentOverride)
+		first(&descentOverride, font.DescentOverride)
+		first(&lineGapOverride, font.LineGapOverride)
+		first(&sizeAdjust, font.SizeAdjust)
+		if path, ok := seen[font.Src]; ok {
+			paths[font.Format] = path
+			continue
+		}
+		// inline
+		var size int64
+		if inlineFormats[font.Format] && o.inline != nil {
+			buf, err := o.inline(font.Src)
+			if err != nil {
+				return nil, err
 			}
-			if font.Stretch != "" && stretch == "" {
-				stretch = font.Stretch
+			size = int64(len(buf))
+			if o.threshold <= 0 || size <= o.threshold {
+				paths[font.Format] = "data:" + font.Format.MIMEType() + ";base64," + base64.StdEncoding.EncodeToString(buf)
+				seen[font.Src] = paths[font.Format]
+				continue
 			}
-			routes = append(routes, Route{
-				Path: path,
-				URL:  font.Src,
-			})
+		}
+		path := o.naming(font)
+		paths[font.Format] = prefix + path
+		seen[font.Src] = paths[font.Format]
+		routes = append(routes, Route{
+			Path:         path,
+			URL:          font.Src,
+			Family:       font.Family,
+			Style:        font.Style,
+			Weight:       font.Weight,
+			Subset:       font.Subset,
+			Format:       font.Format,
+			UnicodeRange: font.Range,
+			Axes:         font.Axes,
+			ByteSize:     size,
+		})
+	}
+	if tech == "" && variations {
+		tech = "variations"
+	}
+	// normalize variable font weight and oblique angle ranges
+	var weightMin, weightMax int
+	if wr, err := ParseWeightRange(weight); err == nil {
+		weightMin, weightMax = wr.Min, wr.Max
+		if wr.Variable() {
+			weight = wr.String()
+		}
+	}
+	var slantMin, slantMax float64
+	if sr, err := ParseStyleRange(style); err == nil && sr.Style == "oblique" {
+		slantMin, slantMax = sr.Min, sr.Max
+		if sr.Variable() {
+			style = sr.String()
 		}
 	}
 	// execute
-	if err := tpl.Execute(w, map[string]interface{}{
-		"family":  family,
-		"style":   style,
-		"weight":  weight,
-		"display": display,
-		"stretch": stretch,
-		"paths":   paths,
+	if err := o.tpl.Execute(w, map[string]interface{}{
+		"family":          family,
+		"style":           style,
+		"weight":          weight,
+		"weightMin":       weightMin,
+		"weightMax":       weightMax,
+		"slantMin":        slantMin,
+		"slantMax":        slantMax,
+		"display":         display,
+		"stretch":         stretch,
+		"ascentOverride":  ascentOverride,
+		"descentOverride": descentOverride,
+		"lineGapOverride": lineGapOverride,
+		"sizeAdjust":      sizeAdjust,
+		"unicodeRange":    unicodeRange,
+		"tech":            tech,
+		"locals":          locals,
+		"paths":           paths,
 	}); err != nil {
 		return nil, err
 	}
 	return routes, nil
 }
 
-// tpl is the stylesheet template.
-var tpl = template.Must(template.New("stylesheet.css.tpl").Funcs(template.FuncMap{
-	"src": func(indent string, m map[string]string) string {
-		var prefix string
-		if path, ok := m["eot"]; ok {
-			prefix = fmt.Sprintf("url('%s');\n%ssrc: url('%s?#iefix') format('embedded-opentype'), ", path, indent, path)
+// HashName returns a route path for the font, in the form of
+// <md5 of src>.format, using the first 7 characters of the md5 hash.
+func HashName(font Font) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(font.Src)))[:7] + font.Format.Extension()
+}
+
+// VerboseName returns a human readable route path for the font, in the form of
+// family[-version][-subset]-weight[-style].format (ie,
+// roboto-v32-latin-700-italic.woff2), similar to the file names used by
+// google-webfonts-helper. The version is determined from the font's src url.
+// Font faces without a subset use the unicode-range hash (see FileName).
+func VerboseName(font Font) string {
+	v := []string{font.Family}
+	if m := versionRE.FindStringSubmatch(font.Src); m != nil {
+		v = append(v, m[1])
+	}
+	if subset := subsetName(font); subset != "" {
+		v = append(v, subset)
+	}
+	v = append(v, font.Weight)
+	if font.Style != "" && font.Style != "normal" {
+		v = append(v, font.Style)
+	}
+	for i := range v {
+		v[i] = strings.Trim(fileNameRE.ReplaceAllString(strings.ToLower(v[i]), "-"), "-")
+	}
+	return strings.Join(v, "-") + font.Format.Extension()
+}
+
+// versionRE matches the version in a font src url.
+var versionRE = regexp.MustCompile(`/(v[0-9]+)/`)
+
+// inlineFormats are the font formats that can be inlined.
+var inlineFormats = map[Format]bool{
+	FormatWOFF2: true,
+	FormatWOFF:  true,
+	FormatTTF:   true,
+	FormatOTF:   true,
+}
+
+// containsFormat returns true when v contains format.
+func containsFormat(v []Format, format Format) bool {
+	for _, z := range v {
+		if z == format {
+			return true
 		}
-		paths := []string{"local('')"}
-		for _, s := range []string{"woff2", "woff", "ttf", "svg"} {
-			if path, ok := m[s]; ok {
-				paths = append(paths, fmt.Sprintf("url('%s') format('%s')", path, s))
+	}
+	return false
+}
+
+// variable returns true when the font face is a variable font face, having a
+// weight range, an oblique angle range, or variable font axes.
+func variable(font Font) bool {
+	if wr, err := font.WeightRange(); err == nil && wr.Variable() {
+		return true
+	}
+	if sr, err := font.StyleRange(); err == nil && sr.Variable() {
+		return true
+	}
+	return len(font.Axes) != 0
+}
+
+// first sets s to v when s is empty.
+func first(s *string, v string) {
+	if *s == "" {
+		*s = v
+	}
+}
+
+// tpl is the stylesheet template.
+var tpl = template.Must(template.New("stylesheet.css.tpl").Funcs(StylesheetFuncs()).Parse(string(stylesheetCSSTpl)))
+
+// StylesheetFuncs returns the template funcs available to stylesheet
+// templates (see WithStylesheetTemplate):
+//
+//	src indent locals paths [tech] - the src descriptor value for the local
+//	                                 names and font file paths, with eot font
+//	                                 files preceded by the iefix src
+//	                                 descriptor, and the woff2, woff, otf, and
+//	                                 ttf font file sources followed by the
+//	                                 tech() src hint, if any.
+func StylesheetFuncs() template.FuncMap {
+	return template.FuncMap{
+		"src": func(indent string, locals []string, m map[Format]string, tech ...string) string {
+			var hint string
+			if t := strings.Join(tech, ", "); t != "" {
+				hint = " tech(" + t + ")"
 			}
-		}
-		return prefix + strings.Join(paths, ", ")
-	},
-}).Parse(string(stylesheetCSSTpl)))
+			var prefix string
+			var paths []string
+			if path, ok := m[FormatEOT]; ok {
+				prefix = fmt.Sprintf("url('%s');\n%ssrc: ", path, indent)
+				paths = append(paths, fmt.Sprintf("url('%s?#iefix') format('embedded-opentype')", path))
+			}
+			for _, name := range locals {
+				paths = append(paths, "local('"+strings.ReplaceAll(name, "'", `\'`)+"')")
+			}
+			for _, s := range []Format{FormatWOFF2, FormatWOFF, FormatOTF, FormatTTF, FormatSVG} {
+				if path, ok := m[s]; ok {
+					src := fmt.Sprintf("url('%s') format('%s')", path, s.CSSFormat())
+					if s != FormatSVG {
+						src += hint
+					}
+					paths = append(paths, src)
+				}
+			}
+			return prefix + strings.Join(paths, ", ")
+		},
+	}
+}
+
+// modernTpl is the modern stylesheet template.
+var modernTpl = template.Must(template.New("stylesheet.modern.css.tpl").Funcs(StylesheetFuncs()).Parse(string(stylesheetModernCSSTpl)))
+
+// stylesheetModernCSSTpl is the embedded modern stylesheet css.
+//
+//go:embed stylesheet.modern.css.tpl
+var stylesheetModernCSSTpl []byte
 
 // stylesheetCSSTpl is the embedded stylesheet css.
 //
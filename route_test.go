@@ -0,0 +1,112 @@
+package webfonts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildRoutesTech(t *testing.T) {
+	fonts := []Font{
+		{Family: "A", Style: "normal", Weight: "400", Format: FormatWOFF2, Src: "https://example.com/a.woff2", Tech: []string{"color-COLRv1"}},
+		{Family: "A", Style: "normal", Weight: "400", Format: FormatTTF, Src: "https://example.com/a.ttf", Tech: []string{"color-COLRv1"}},
+		{Family: "A", Style: "normal", Weight: "400", Format: FormatSVG, Src: "https://example.com/a.svg"},
+	}
+	tests := []struct {
+		name string
+		opts []RouteOption
+		exp  []string
+	}{
+		{
+			"default",
+			nil,
+			[]string{
+				"format('woff2') tech(color-COLRv1), ",
+				"format('truetype') tech(color-COLRv1), ",
+				"format('svg');",
+			},
+		},
+		{
+			"modern",
+			[]RouteOption{WithModernStylesheet(true)},
+			[]string{
+				"src: url('/a.woff2') format('woff2') tech(color-COLRv1);",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]RouteOption{WithRouteNaming(func(font Font) string {
+				return "a" + font.Format.Extension()
+			})}, test.opts...)
+			var stylesheet string
+			err := BuildRoutes("/", fonts, func(_ string, buf []byte, _ []Route) error {
+				stylesheet = string(buf)
+				return nil
+			}, opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			for _, s := range test.exp {
+				if !strings.Contains(stylesheet, s) {
+					t.Errorf("expected stylesheet to contain %q, got:\n%s", s, stylesheet)
+				}
+			}
+			if n := strings.Count(stylesheet, "tech("); n != strings.Count(strings.Join(test.exp, ""), "tech(") {
+				t.Errorf("expected tech() only on the expected sources, got:\n%s", stylesheet)
+			}
+		})
+	}
+}
+
+func TestBuildRoutesNumberedSlices(t *testing.T) {
+	fonts, err := FontsFromStylesheetReader(strings.NewReader(`/* [0] */
+@font-face {
+  font-family: 'Noto Sans JP';
+  font-style: normal;
+  font-weight: 400;
+  src: url(https://example.com/0.woff2) format('woff2');
+  unicode-range: U+25ee8, U+25f23;
+}
+/* [1] */
+@font-face {
+  font-family: 'Noto Sans JP';
+  font-style: normal;
+  font-weight: 400;
+  src: url(https://example.com/1.woff2) format('woff2');
+  unicode-range: U+1f235-1f23b, U+1f240-1f248;
+}
+/* latin */
+@font-face {
+  font-family: 'Noto Sans JP';
+  font-style: normal;
+  font-weight: 400;
+  src: url(https://example.com/latin.woff2) format('woff2');
+  unicode-range: U+0000-00FF;
+}`))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var stylesheet string
+	var routes []Route
+	err = BuildRoutes("/", fonts, func(_ string, buf []byte, v []Route) error {
+		stylesheet, routes = string(buf), v
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := strings.Count(stylesheet, "@font-face"); n != 3 {
+		t.Errorf("expected 3 @font-face rules, got %d:\n%s", n, stylesheet)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got: %d", len(routes))
+	}
+	for i, exp := range []string{"U+25ee8, U+25f23", "U+1f235-1f23b, U+1f240-1f248", "U+0000-00FF"} {
+		if !strings.Contains(stylesheet, "unicode-range: "+exp+";") {
+			t.Errorf("expected stylesheet to contain unicode-range %s, got:\n%s", exp, stylesheet)
+		}
+		if s := strings.Join(routes[i].UnicodeRange, ", "); s != exp {
+			t.Errorf("route %d: expected unicode-range %s, got: %s", i, exp, s)
+		}
+	}
+}
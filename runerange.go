@@ -0,0 +1,129 @@
+package webfonts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kenshaw/webfonts/convert"
+)
+
+// RuneRange is an inclusive range of runes, as parsed from a css
+// unicode-range value.
+type RuneRange struct {
+	Lo rune `json:"lo"`
+	Hi rune `json:"hi"`
+}
+
+// ParseRuneRange parses a css unicode-range value (ie, "U+0000-00FF",
+// "U+2212", "U+4??").
+func ParseRuneRange(s string) (RuneRange, error) {
+	lo, hi, err := convert.ParseUnicodeRange(s)
+	if err != nil {
+		return RuneRange{}, fmt.Errorf("%q: %w", s, err)
+	}
+	return RuneRange{Lo: lo, Hi: hi}, nil
+}
+
+// Contains returns true when the range contains the rune.
+func (rr RuneRange) Contains(r rune) bool {
+	return rr.Lo <= r && r <= rr.Hi
+}
+
+// String satisfies the fmt.Stringer interface.
+//
+// Returns the range as a css unicode-range value.
+func (rr RuneRange) String() string {
+	if rr.Lo == rr.Hi {
+		return fmt.Sprintf("U+%04X", rr.Lo)
+	}
+	return fmt.Sprintf("U+%04X-%04X", rr.Lo, rr.Hi)
+}
+
+// RuneRanges are rune ranges.
+type RuneRanges []RuneRange
+
+// ParseRuneRanges parses css unicode-range values, such as a font face's
+// Range. Each value may contain multiple comma separated ranges.
+func ParseRuneRanges(v ...string) (RuneRanges, error) {
+	var ranges RuneRanges
+	for _, s := range v {
+		for _, z := range strings.Split(s, ",") {
+			if z = strings.TrimSpace(z); z == "" {
+				continue
+			}
+			rr, err := ParseRuneRange(z)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, rr)
+		}
+	}
+	return ranges, nil
+}
+
+// Contains returns true when any of the ranges contains the rune.
+func (ranges RuneRanges) Contains(r rune) bool {
+	for _, rr := range ranges {
+		if rr.Contains(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// CoversText returns true when the ranges contain every rune in the text.
+func (ranges RuneRanges) CoversText(s string) bool {
+	for _, r := range s {
+		if !ranges.Contains(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects returns true when the ranges contain any rune in the text.
+func (ranges RuneRanges) Intersects(s string) bool {
+	for _, r := range s {
+		if ranges.Contains(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlaps returns true when any of the ranges overlaps any of the other
+// ranges.
+func (ranges RuneRanges) Overlaps(other RuneRanges) bool {
+	for _, a := range ranges {
+		for _, b := range other {
+			if a.Lo <= b.Hi && b.Lo <= a.Hi {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RuneRanges returns the font face's parsed unicode-range. A font face
+// without a unicode-range returns nil ranges, and covers all runes.
+func (font Font) RuneRanges() (RuneRanges, error) {
+	return ParseRuneRanges(font.Range...)
+}
+
+// FontsForText returns the font faces needed to render the text, omitting
+// font faces whose unicode-range contains none of the text's runes (ie, the
+// subset faces a browser would not load for the text). Font faces without a
+// unicode-range are always returned.
+func FontsForText(fonts []Font, text string) ([]Font, error) {
+	var v []Font
+	for _, font := range fonts {
+		ranges, err := font.RuneRanges()
+		if err != nil {
+			return nil, err
+		}
+		if ranges == nil || ranges.Intersects(text) {
+			v = append(v, font)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,80 @@
+package webfonts
+
+import (
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ScanHTML scans the html document for <link> tags and @import rules in
+// <style> tags referencing fonts.googleapis.com stylesheets, returning the
+// parsed query for each referenced stylesheet (see ParseQuery), in document
+// order. Combined with Bundle and RewriteStylesheet, allows a page's fonts to
+// be self-hosted (see LocalizeHTML).
+func ScanHTML(r io.Reader) ([]*Query, error) {
+	urls, err := scanHTML(r)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]*Query, len(urls))
+	for i, urlstr := range urls {
+		if queries[i], err = ParseQuery(urlstr); err != nil {
+			return nil, err
+		}
+	}
+	return queries, nil
+}
+
+// scanHTML returns the distinct fonts.googleapis.com stylesheet urls
+// referenced by the html document.
+func scanHTML(r io.Reader) ([]string, error) {
+	var urls []string
+	add := func(urlstr string) {
+		if isGoogleFontsStylesheet(urlstr) && !contains(urls, urlstr) {
+			urls = append(urls, urlstr)
+		}
+	}
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return nil, err
+			}
+			return urls, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok := z.Token(); tok.Data {
+			case "link":
+				for _, attr := range tok.Attr {
+					if attr.Key == "href" {
+						add(attr.Val)
+					}
+				}
+			case "style":
+				if z.Next() != html.TextToken {
+					continue
+				}
+				for _, m := range importRE.FindAllStringSubmatch(string(z.Text()), -1) {
+					add(m[1] + m[2] + m[3])
+				}
+			}
+		}
+	}
+}
+
+// importRE matches @import rules.
+var importRE = regexp.MustCompile(`@import\s+(?:url\(\s*['"]?([^'")]+)['"]?\s*\)|"([^"]+)"|'([^']+)')`)
+
+// isGoogleFontsStylesheet returns true when the url is a fonts.googleapis.com
+// css or css2 api url.
+func isGoogleFontsStylesheet(urlstr string) bool {
+	u, err := url.Parse(strings.TrimSpace(urlstr))
+	if err != nil || !strings.EqualFold(u.Host, "fonts.googleapis.com") {
+		return false
+	}
+	return path.Base(u.Path) == "css" || path.Base(u.Path) == "css2"
+}
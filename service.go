@@ -0,0 +1,50 @@
+package webfonts
+
+import (
+	"context"
+
+	gfonts "google.golang.org/api/webfonts/v1"
+)
+
+// Service is a Google Fonts Developer API service, used by the google
+// provider to list the available font families.
+//
+// A custom service (such as a test double, see the webfontstest package) can
+// be used with a client by using WithService.
+type Service interface {
+	// List lists the available font families.
+	List(ctx context.Context, opts *ListOptions) ([]Family, error)
+}
+
+// googleService is the Google Fonts Developer API service.
+type googleService struct {
+	svc *gfonts.Service
+}
+
+// List satisfies the Service interface.
+func (s googleService) List(ctx context.Context, o *ListOptions) ([]Family, error) {
+	// build call
+	call := s.svc.Webfonts.List().Context(ctx)
+	if o.Sort != "" {
+		call = call.Sort(o.Sort)
+	}
+	if o.Subset != "" {
+		call = call.Subset(o.Subset)
+	}
+	if len(o.Families) != 0 {
+		call = call.Family(o.Families...)
+	}
+	if len(o.Capabilities) != 0 {
+		call = call.Capability(o.Capabilities...)
+	}
+	// retrieve
+	res, err := call.Do()
+	if err != nil {
+		return nil, wrapAPIError(s.svc.BasePath+"v1/webfonts", err)
+	}
+	families := make([]Family, len(res.Items))
+	for i, f := range res.Items {
+		families[i] = FamilyFromWebfont(f)
+	}
+	return families, nil
+}
@@ -0,0 +1,329 @@
+// Package specimen renders font specimens, rasterizing sample text with a
+// font face to png or svg images, for previewing fonts in catalogs and font
+// pickers without loading every font in the browser.
+package specimen
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kenshaw/webfonts"
+	"github.com/kenshaw/webfonts/convert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Default specimen options.
+const (
+	DefaultSize    = 32.0
+	DefaultPadding = 8
+)
+
+// Option is a specimen option.
+type Option func(*Specimen)
+
+// WithSize is a specimen option to set the font size in pixels (default:
+// DefaultSize).
+func WithSize(size float64) Option {
+	return func(s *Specimen) {
+		s.size = size
+	}
+}
+
+// WithPadding is a specimen option to set the padding around the text in
+// pixels (default: DefaultPadding).
+func WithPadding(padding int) Option {
+	return func(s *Specimen) {
+		s.padding = padding
+	}
+}
+
+// WithForeground is a specimen option to set the text color (default:
+// black).
+func WithForeground(fg color.Color) Option {
+	return func(s *Specimen) {
+		s.fg = fg
+	}
+}
+
+// WithBackground is a specimen option to set the background color (default:
+// transparent).
+func WithBackground(bg color.Color) Option {
+	return func(s *Specimen) {
+		s.bg = bg
+	}
+}
+
+// Specimen is a font specimen, the laid out glyphs of sample text for a font
+// face.
+type Specimen struct {
+	font    *sfnt.Font
+	buf     sfnt.Buffer
+	size    float64
+	padding int
+	fg      color.Color
+	bg      color.Color
+	glyphs  []glyph
+	width   int
+	height  int
+}
+
+// glyph is a laid out glyph.
+type glyph struct {
+	index sfnt.GlyphIndex
+	dot   fixed.Point26_6
+}
+
+// New creates a specimen for the woff2, woff, ttf, or otf font data,
+// laying out the text (which may contain multiple lines).
+func New(buf []byte, text string, opts ...Option) (*Specimen, error) {
+	// decompress to sfnt
+	var err error
+	switch convert.Format(buf) {
+	case "woff2":
+		if buf, err = convert.WOFF2ToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "woff":
+		if buf, err = convert.WOFFToSFNT(buf); err != nil {
+			return nil, err
+		}
+	case "ttf", "otf":
+	default:
+		return nil, convert.ErrUnsupportedFormat
+	}
+	f, err := sfnt.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	s := &Specimen{
+		font:    f,
+		size:    DefaultSize,
+		padding: DefaultPadding,
+		fg:      color.Black,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if err := s.layout(text); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Family retrieves the font face for the family from the client, limited to
+// the glyphs needed for the text (see webfonts.WithText), and creates a
+// specimen for it.
+func Family(ctx context.Context, cl *webfonts.Client, family, text string, opts ...Option) (*Specimen, error) {
+	fonts, err := cl.Faces(ctx, family, webfonts.WithText(text))
+	switch {
+	case err != nil:
+		return nil, err
+	case len(fonts) == 0:
+		return nil, &webfonts.FamilyError{Family: family, Err: webfonts.ErrFormatNotAvailable}
+	}
+	buf, err := cl.FontFile(ctx, fonts[0])
+	if err != nil {
+		return nil, err
+	}
+	return New(buf, text, opts...)
+}
+
+// layout lays out the glyphs for the text.
+func (s *Specimen) layout(text string) error {
+	ppem := s.ppem()
+	m, err := s.font.Metrics(&s.buf, ppem, font.HintingNone)
+	if err != nil {
+		return err
+	}
+	pad := fixed.I(s.padding)
+	dot := fixed.Point26_6{X: pad, Y: pad + m.Ascent}
+	width, lines := fixed.Int26_6(0), strings.Split(text, "\n")
+	for i, line := range lines {
+		if i != 0 {
+			dot.X, dot.Y = pad, dot.Y+m.Height
+		}
+		prev := sfnt.GlyphIndex(0)
+		for j, r := range line {
+			index, err := s.font.GlyphIndex(&s.buf, r)
+			if err != nil {
+				return err
+			}
+			if j != 0 {
+				// fonts without kerning return an error
+				if kern, err := s.font.Kern(&s.buf, prev, index, ppem, font.HintingNone); err == nil {
+					dot.X += kern
+				}
+			}
+			s.glyphs = append(s.glyphs, glyph{index: index, dot: dot})
+			advance, err := s.font.GlyphAdvance(&s.buf, index, ppem, font.HintingNone)
+			if err != nil {
+				return err
+			}
+			dot.X, prev = dot.X+advance, index
+		}
+		if dot.X > width {
+			width = dot.X
+		}
+	}
+	s.width = (width + pad).Ceil()
+	s.height = (dot.Y + m.Descent + pad).Ceil()
+	return nil
+}
+
+// ppem returns the specimen's pixels per em.
+func (s *Specimen) ppem() fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(s.size * 64))
+}
+
+// Bounds returns the specimen's image bounds.
+func (s *Specimen) Bounds() image.Rectangle {
+	return image.Rect(0, 0, s.width, s.height)
+}
+
+// segments calls f with the outline segments of each laid out glyph, offset
+// to the glyph's position.
+func (s *Specimen) segments(f func(sfnt.Segment)) error {
+	ppem := s.ppem()
+	for _, g := range s.glyphs {
+		segments, err := s.font.LoadGlyph(&s.buf, g.index, ppem, nil)
+		if err != nil {
+			return err
+		}
+		for _, seg := range segments {
+			for i := range seg.Args {
+				seg.Args[i] = seg.Args[i].Add(g.dot)
+			}
+			f(seg)
+		}
+	}
+	return nil
+}
+
+// Image rasterizes the specimen.
+func (s *Specimen) Image() (*image.RGBA, error) {
+	bounds := s.Bounds()
+	img := image.NewRGBA(bounds)
+	if s.bg != nil {
+		draw.Draw(img, bounds, image.NewUniform(s.bg), image.Point{}, draw.Src)
+	}
+	z := vector.NewRasterizer(s.width, s.height)
+	err := s.segments(func(seg sfnt.Segment) {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			z.ClosePath()
+			z.MoveTo(float32(seg.Args[0].X)/64, float32(seg.Args[0].Y)/64)
+		case sfnt.SegmentOpLineTo:
+			z.LineTo(float32(seg.Args[0].X)/64, float32(seg.Args[0].Y)/64)
+		case sfnt.SegmentOpQuadTo:
+			z.QuadTo(
+				float32(seg.Args[0].X)/64, float32(seg.Args[0].Y)/64,
+				float32(seg.Args[1].X)/64, float32(seg.Args[1].Y)/64,
+			)
+		case sfnt.SegmentOpCubeTo:
+			z.CubeTo(
+				float32(seg.Args[0].X)/64, float32(seg.Args[0].Y)/64,
+				float32(seg.Args[1].X)/64, float32(seg.Args[1].Y)/64,
+				float32(seg.Args[2].X)/64, float32(seg.Args[2].Y)/64,
+			)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	z.ClosePath()
+	z.Draw(img, bounds, image.NewUniform(s.fg), image.Point{})
+	return img, nil
+}
+
+// PNG writes the rasterized specimen to w as a png image.
+func (s *Specimen) PNG(w io.Writer) error {
+	img, err := s.Image()
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// SVG writes the specimen to w as a svg image, with the glyph outlines as a
+// single path.
+func (s *Specimen) SVG(w io.Writer) error {
+	var d strings.Builder
+	err := s.segments(func(seg sfnt.Segment) {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			if d.Len() != 0 {
+				d.WriteString("Z")
+			}
+			d.WriteString("M" + point(seg.Args[0]))
+		case sfnt.SegmentOpLineTo:
+			d.WriteString("L" + point(seg.Args[0]))
+		case sfnt.SegmentOpQuadTo:
+			d.WriteString("Q" + point(seg.Args[0]) + " " + point(seg.Args[1]))
+		case sfnt.SegmentOpCubeTo:
+			d.WriteString("C" + point(seg.Args[0]) + " " + point(seg.Args[1]) + " " + point(seg.Args[2]))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if d.Len() != 0 {
+		d.WriteString("Z")
+	}
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, s.width, s.height, s.width, s.height)
+	if s.bg != nil {
+		fmt.Fprintf(w, `<rect width="100%%" height="100%%"%s/>`, fill(s.bg))
+	}
+	_, err = fmt.Fprintf(w, `<path d="%s"%s/></svg>`+"\n", d.String(), fill(s.fg))
+	return err
+}
+
+// point formats a point for a svg path.
+func point(p fixed.Point26_6) string {
+	return coord(p.X) + "," + coord(p.Y)
+}
+
+// coord formats a coordinate for a svg path.
+func coord(v fixed.Int26_6) string {
+	return strconv.FormatFloat(float64(v)/64, 'f', -1, 64)
+}
+
+// fill returns the svg fill attributes for the color.
+func fill(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	s := fmt.Sprintf(` fill="#%02x%02x%02x"`, n.R, n.G, n.B)
+	if n.A != 0xff {
+		s += ` fill-opacity="` + strconv.FormatFloat(float64(n.A)/0xff, 'f', 3, 64) + `"`
+	}
+	return s
+}
+
+// PNG renders the text with the woff2, woff, ttf, or otf font data, writing
+// a png image to w.
+func PNG(w io.Writer, buf []byte, text string, opts ...Option) error {
+	s, err := New(buf, text, opts...)
+	if err != nil {
+		return err
+	}
+	return s.PNG(w)
+}
+
+// SVG renders the text with the woff2, woff, ttf, or otf font data, writing
+// a svg image to w.
+func SVG(w io.Writer, buf []byte, text string, opts ...Option) error {
+	s, err := New(buf, text, opts...)
+	if err != nil {
+		return err
+	}
+	return s.SVG(w)
+}
@@ -0,0 +1,112 @@
+package webfonts
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultObliqueAngle is the css default oblique angle, in degrees.
+const DefaultObliqueAngle = 14.0
+
+// StyleRange is a font-style descriptor's style and slant angle range. The
+// angle range is only set for oblique styles, and variable font faces with a
+// slant axis have an oblique angle range (ie, "oblique 0deg 10deg").
+type StyleRange struct {
+	// Style is the style keyword ("normal", "italic", or "oblique").
+	Style string `json:"style"`
+	// Min is the minimum oblique angle, in degrees.
+	Min float64 `json:"min,omitempty"`
+	// Max is the maximum oblique angle, in degrees.
+	Max float64 `json:"max,omitempty"`
+}
+
+// ParseStyleRange parses a font-style descriptor value (ie, "normal",
+// "italic", "oblique", "oblique 10deg", "oblique 0deg 10deg"). An oblique
+// style without an angle has the default oblique angle.
+func ParseStyleRange(s string) (StyleRange, error) {
+	v := strings.Fields(strings.ToLower(s))
+	switch {
+	case len(v) == 0:
+		return StyleRange{Style: "normal"}, nil
+	case len(v) == 1 && (v[0] == "normal" || v[0] == "italic"):
+		return StyleRange{Style: v[0]}, nil
+	case v[0] != "oblique" || 3 < len(v):
+		return StyleRange{}, fmt.Errorf("%q: %w", s, ErrInvalidStyle)
+	case len(v) == 1:
+		return StyleRange{Style: "oblique", Min: DefaultObliqueAngle, Max: DefaultObliqueAngle}, nil
+	}
+	sr := StyleRange{Style: "oblique"}
+	var err error
+	if sr.Min, err = parseAngle(v[1]); err != nil {
+		return StyleRange{}, fmt.Errorf("%q: %w", s, err)
+	}
+	sr.Max = sr.Min
+	if len(v) == 3 {
+		if sr.Max, err = parseAngle(v[2]); err != nil {
+			return StyleRange{}, fmt.Errorf("%q: %w", s, err)
+		}
+	}
+	if sr.Max < sr.Min {
+		sr.Min, sr.Max = sr.Max, sr.Min
+	}
+	return sr, nil
+}
+
+// Variable returns true when the style range is a variable font oblique
+// angle range.
+func (sr StyleRange) Variable() bool {
+	return sr.Min != sr.Max
+}
+
+// String satisfies the fmt.Stringer interface.
+//
+// Returns the style range as a font-style descriptor value.
+func (sr StyleRange) String() string {
+	switch {
+	case sr.Style != "oblique":
+		return sr.Style
+	case sr.Variable():
+		return "oblique " + formatAngle(sr.Min) + " " + formatAngle(sr.Max)
+	}
+	return "oblique " + formatAngle(sr.Min)
+}
+
+// StyleRange returns the font face's parsed font-style descriptor.
+func (font Font) StyleRange() (StyleRange, error) {
+	return ParseStyleRange(font.Style)
+}
+
+// angleUnits are the css angle units, and their size in degrees.
+var angleUnits = map[string]float64{
+	"deg":  1,
+	"grad": 0.9,
+	"rad":  180 / math.Pi,
+	"turn": 360,
+}
+
+// parseAngle parses a css angle (ie, "10deg", "0.2rad"), returning the angle
+// in degrees.
+func parseAngle(s string) (float64, error) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return 'a' <= r && r <= 'z'
+	})
+	if i == -1 {
+		return 0, ErrInvalidStyle
+	}
+	unit, ok := angleUnits[s[i:]]
+	if !ok {
+		return 0, ErrInvalidStyle
+	}
+	f, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, ErrInvalidStyle
+	}
+	return f * unit, nil
+}
+
+// formatAngle formats an angle in degrees.
+func formatAngle(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64) + "deg"
+}
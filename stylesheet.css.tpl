@@ -8,5 +8,20 @@
 {{- if .stretch }}
   font-stretch: {{ .stretch }};
 {{- end }}
-  src: {{ src "  " .paths }};
+{{- if .ascentOverride }}
+  ascent-override: {{ .ascentOverride }};
+{{- end }}
+{{- if .descentOverride }}
+  descent-override: {{ .descentOverride }};
+{{- end }}
+{{- if .lineGapOverride }}
+  line-gap-override: {{ .lineGapOverride }};
+{{- end }}
+{{- if .sizeAdjust }}
+  size-adjust: {{ .sizeAdjust }};
+{{- end }}
+  src: {{ src "  " .locals .paths .tech }};
+{{- if .unicodeRange }}
+  unicode-range: {{ .unicodeRange }};
+{{- end }}
 }
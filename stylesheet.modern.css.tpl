@@ -0,0 +1,25 @@
+@font-face {
+  font-family: '{{ .family }}';
+  font-style: {{ .style }};
+  font-weight: {{ .weight }};
+  font-display: {{ or .display "swap" }};
+{{- if .stretch }}
+  font-stretch: {{ .stretch }};
+{{- end }}
+{{- if .ascentOverride }}
+  ascent-override: {{ .ascentOverride }};
+{{- end }}
+{{- if .descentOverride }}
+  descent-override: {{ .descentOverride }};
+{{- end }}
+{{- if .lineGapOverride }}
+  line-gap-override: {{ .lineGapOverride }};
+{{- end }}
+{{- if .sizeAdjust }}
+  size-adjust: {{ .sizeAdjust }};
+{{- end }}
+  src: {{ src "  " .locals .paths .tech }};
+{{- if .unicodeRange }}
+  unicode-range: {{ .unicodeRange }};
+{{- end }}
+}
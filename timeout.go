@@ -0,0 +1,44 @@
+package webfonts
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultRequestTimeout is the default timeout for each upstream request.
+const DefaultRequestTimeout = 1 * time.Minute
+
+// timeoutTransport is a http transport that applies a timeout to each
+// request, including reading the response body.
+type timeoutTransport struct {
+	transport http.RoundTripper
+	timeout   time.Duration
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	res, err := t.transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelBody is a response body that cancels its request's context when
+// closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close satisfies the io.Closer interface.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
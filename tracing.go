@@ -0,0 +1,39 @@
+package webfonts
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation name of the client's tracer.
+const tracerName = "github.com/kenshaw/webfonts"
+
+// Span attribute keys.
+const (
+	attrFamily = attribute.Key("webfonts.family")
+	attrFormat = attribute.Key("webfonts.format")
+	attrURL    = attribute.Key("webfonts.url")
+)
+
+// noopTracer is the tracer used when the client has no tracer provider.
+var noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+// startSpan starts a span for a client operation.
+func (cl *Client) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return cl.tracer.Start(ctx, "webfonts."+name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span, recording the error when not nil. Used as:
+//
+//	defer endSpan(span, &err)
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
@@ -0,0 +1,139 @@
+package webfonts
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/chromedp/verhist"
+)
+
+// FallbackUserAgents are the chrome user agents, keyed by platform, used when
+// the current chrome user agent cannot be resolved.
+var FallbackUserAgents = map[string]string{
+	"linux": "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+	"mac":   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+	"win":   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+}
+
+// buildUserAgent returns the client's user agent. When not set (see
+// WithDefaultUserAgent), the current chrome user agent is resolved on first
+// use, falling back to the linux fallback user agent (see
+// FallbackUserAgents) when the user agent cannot be resolved or the client
+// uses a local source.
+//
+// When the user agent cannot be resolved (ie, a transient network failure),
+// the fallback user agent is returned, and resolution is retried on a later
+// call after userAgentRetryInterval.
+func (cl *Client) buildUserAgent(ctx context.Context) string {
+	cl.userAgentMu.Lock()
+	defer cl.userAgentMu.Unlock()
+	switch {
+	case cl.userAgent != "":
+		return cl.userAgent
+	case cl.local != nil:
+		cl.userAgent = FallbackUserAgents["linux"]
+		return cl.userAgent
+	case time.Now().Before(cl.userAgentRetry):
+		return FallbackUserAgents["linux"]
+	}
+	userAgent, err := verhist.UserAgent(ctx, "linux", "stable", verhist.WithTransport(cl.transport))
+	if err != nil {
+		cl.userAgentRetry = time.Now().Add(userAgentRetryInterval)
+		return FallbackUserAgents["linux"]
+	}
+	cl.userAgent = userAgent
+	return cl.userAgent
+}
+
+// queryUserAgent returns the query's user agent (see WithUserAgent), or the
+// client's user agent when the query does not have a user agent.
+func (cl *Client) queryUserAgent(ctx context.Context, q *Query) string {
+	if q.UserAgent != "" {
+		return q.UserAgent
+	}
+	return cl.buildUserAgent(ctx)
+}
+
+// userAgentRetryInterval is the minimum interval between attempts to resolve
+// the current chrome user agent.
+const userAgentRetryInterval = 1 * time.Minute
+
+// DefaultUserAgents returns the default user agents used to retrieve each font
+// format, keyed by format.
+func DefaultUserAgents() map[Format]string {
+	return map[Format]string{
+		FormatEOT:   UserAgentEOT,
+		FormatSVG:   UserAgentSVG,
+		FormatTTF:   UserAgentTTF,
+		FormatOTF:   UserAgentOTF,
+		FormatWOFF2: UserAgentWOFF2,
+		FormatWOFF:  UserAgentWOFF,
+	}
+}
+
+// UserAgents returns a copy of the user agents used to retrieve each font
+// format, keyed by format (see WithUserAgents).
+func (cl *Client) UserAgents() map[Format]string {
+	userAgents := make(map[Format]string, len(cl.userAgents))
+	for format, userAgent := range cl.userAgents {
+		userAgents[format] = userAgent
+	}
+	return userAgents
+}
+
+// optionalFormats are the formats only retrieved by bulk operations when set
+// with WithFormats, as the default provider does not serve them.
+var optionalFormats = []Format{FormatOTF}
+
+// formats returns the formats retrieved by bulk operations, either the
+// formats set with WithFormats that have user agents, or all formats with
+// user agents other than the optional formats.
+func (cl *Client) formats() []Format {
+	var formats []Format
+	if cl.priority == nil {
+		for _, format := range cl.userAgentFormats() {
+			if !containsFormat(optionalFormats, format) {
+				formats = append(formats, format)
+			}
+		}
+		return formats
+	}
+	for _, format := range cl.priority {
+		if _, ok := cl.userAgents[format]; ok && !containsFormat(formats, format) {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// userAgentFormats returns the formats with user agents, with the common
+// formats first.
+func (cl *Client) userAgentFormats() []Format {
+	var formats, other []Format
+	for _, format := range []Format{FormatEOT, FormatSVG, FormatTTF, FormatOTF, FormatWOFF2, FormatWOFF} {
+		if _, ok := cl.userAgents[format]; ok {
+			formats = append(formats, format)
+		}
+	}
+	for format := range cl.userAgents {
+		if _, ok := DefaultUserAgents()[format]; !ok {
+			other = append(other, format)
+		}
+	}
+	sort.Slice(other, func(i, j int) bool {
+		return other[i] < other[j]
+	})
+	return append(formats, other...)
+}
+
+// userAgentFormat returns the font format retrieved by the user agent,
+// defaulting to woff2.
+func (cl *Client) userAgentFormat(userAgent string) Format {
+	for _, format := range cl.userAgentFormats() {
+		if cl.userAgents[format] == userAgent {
+			return format
+		}
+	}
+	return FormatWOFF2
+}
@@ -0,0 +1,16 @@
+package webfonts
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryUserAgent(t *testing.T) {
+	cl := NewClient()
+	if s := cl.queryUserAgent(context.Background(), &Query{UserAgent: "test"}); s != "test" {
+		t.Errorf("expected %q, got: %q", "test", s)
+	}
+	if cl.userAgent != "" || !cl.userAgentRetry.IsZero() {
+		t.Errorf("expected the default user agent to not be resolved")
+	}
+}
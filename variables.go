@@ -0,0 +1,91 @@
+package webfonts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// VariableSyntax is a font stack variable syntax (see WriteVariables).
+type VariableSyntax string
+
+// Variable syntaxes.
+const (
+	// VariablesCSS is css custom properties (ie, --font-roboto).
+	VariablesCSS VariableSyntax = "css"
+	// VariablesSCSS is scss variables (ie, $font-roboto) and a $fonts map.
+	VariablesSCSS VariableSyntax = "scss"
+	// VariablesLESS is less variables (ie, @font-roboto).
+	VariablesLESS VariableSyntax = "less"
+)
+
+// Extension returns the file extension for the variable syntax (ie,
+// ".scss").
+func (syntax VariableSyntax) Extension() string {
+	return "." + string(syntax)
+}
+
+// WriteVariables writes a font stack variable for each of the families using
+// the syntax, allowing design systems to refer to the families
+// programmatically. Each font stack is the quoted family name followed by the
+// generic family returned by generic (default: sans-serif). Variables are
+// named font-<family> (ie, font-open-sans).
+func WriteVariables(w io.Writer, syntax VariableSyntax, families []string, generic func(string) string) error {
+	// build font stacks
+	names := make([]string, len(families))
+	stacks := make([]string, len(families))
+	for i, family := range families {
+		fallback := "sans-serif"
+		if generic != nil {
+			if s := generic(family); s != "" {
+				fallback = s
+			}
+		}
+		names[i] = "font-" + mirrorDir(family)
+		stacks[i] = "'" + strings.ReplaceAll(family, "'", `\'`) + "', " + fallback
+	}
+	// write
+	buf := new(bytes.Buffer)
+	switch syntax {
+	case VariablesCSS:
+		buf.WriteString(":root {\n")
+		for i := range names {
+			fmt.Fprintf(buf, "  --%s: %s;\n", names[i], stacks[i])
+		}
+		buf.WriteString("}\n")
+	case VariablesSCSS:
+		for i := range names {
+			fmt.Fprintf(buf, "$%s: %s;\n", names[i], stacks[i])
+		}
+		buf.WriteString("$fonts: (\n")
+		for i := range names {
+			fmt.Fprintf(buf, "  %q: $%s,\n", families[i], names[i])
+		}
+		buf.WriteString(");\n")
+	case VariablesLESS:
+		for i := range names {
+			fmt.Fprintf(buf, "@%s: %s;\n", names[i], stacks[i])
+		}
+	default:
+		return fmt.Errorf("%q: %w", syntax, ErrInvalidVariableSyntax)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// GenericFamily returns the css generic font family for the font category
+// (see Family.Category), defaulting to sans-serif.
+func GenericFamily(category string) string {
+	switch strings.ToLower(category) {
+	case "serif":
+		return "serif"
+	case "monospace":
+		return "monospace"
+	case "handwriting":
+		return "cursive"
+	case "display":
+		return "system-ui"
+	}
+	return "sans-serif"
+}
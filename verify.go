@@ -0,0 +1,95 @@
+package webfonts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kenshaw/webfonts/convert"
+)
+
+// VerifyError is a font file verification error.
+type VerifyError struct {
+	// URL is the font's src url.
+	URL string
+	// Format is the font's declared format.
+	Format Format
+	// Detected is the detected format of the font file.
+	Detected Format
+	// Missing are the font's declared unicode-range values not covered by the
+	// font file's cmap.
+	Missing []string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error satisfies the error interface.
+func (err *VerifyError) Error() string {
+	s := "unable to verify " + err.URL
+	switch {
+	case err.Err == ErrFormatMismatch:
+		return fmt.Sprintf("%s: %v: declared %s, detected %s", s, err.Err, err.Format, err.Detected)
+	case err.Err == ErrRangeNotCovered:
+		return fmt.Sprintf("%s: %v: %s", s, err.Err, strings.Join(err.Missing, ", "))
+	}
+	return s + ": " + err.Err.Error()
+}
+
+// Unwrap satisfies the errors.Unwrap interface.
+func (err *VerifyError) Unwrap() error {
+	return err.Err
+}
+
+// Is satisfies the errors.Is interface. A verify error is always
+// ErrVerifyFailed.
+func (err *VerifyError) Is(target error) bool {
+	return target == ErrVerifyFailed
+}
+
+// VerifyFont verifies that the font file data's magic bytes match the font's
+// declared format, and that the font file's cmap covers each of the font's
+// declared unicode-range values. A unicode-range value is covered when the
+// cmap maps at least one rune in the range. Returns a *VerifyError on failure.
+//
+// Coverage is only verified for woff2, woff, ttf, and otf font files.
+func VerifyFont(font Font, buf []byte) error {
+	newErr := func(err error) *VerifyError {
+		return &VerifyError{
+			URL:    font.Src,
+			Format: font.Format,
+			Err:    err,
+		}
+	}
+	// check format
+	detected := Format(convert.Format(buf))
+	if detected != font.Format {
+		err := newErr(ErrFormatMismatch)
+		err.Detected = detected
+		return err
+	}
+	switch {
+	case len(font.Range) == 0,
+		detected != FormatWOFF2 && detected != FormatWOFF && detected != FormatTTF && detected != FormatOTF:
+		return nil
+	}
+	// check coverage
+	info, err := convert.InspectBytes(buf)
+	if err != nil {
+		return newErr(err)
+	}
+	var missing []string
+	for _, s := range font.Range {
+		lo, hi, err := convert.ParseUnicodeRange(s)
+		if err != nil {
+			return newErr(err)
+		}
+		if !info.HasRange(lo, hi) {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) != 0 {
+		err := newErr(ErrRangeNotCovered)
+		err.Missing = missing
+		return err
+	}
+	return nil
+}
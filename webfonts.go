@@ -4,15 +4,19 @@ package webfonts
 
 import (
 	"context"
-
-	gfonts "google.golang.org/api/webfonts/v1"
 )
 
-// Available retrieves all available webfonts.
-func Available(ctx context.Context, opts ...ClientOption) ([]*gfonts.Webfont, error) {
+// Available retrieves all available font families.
+func Available(ctx context.Context, opts ...ClientOption) ([]Family, error) {
 	return NewClient(opts...).Available(ctx)
 }
 
+// Popular retrieves the n most popular available font families. See
+// Client.Popular.
+func Popular(ctx context.Context, n int, opts ...ClientOption) ([]Family, error) {
+	return NewClient(opts...).Popular(ctx, n)
+}
+
 // Faces retrieves the font faces for the specified family.
 func Faces(ctx context.Context, family string, opts ...ClientOption) ([]Font, error) {
 	return NewClient(opts...).Faces(ctx, family)
@@ -24,8 +28,8 @@ func All(ctx context.Context, family string, opts ...ClientOption) ([]Font, erro
 	return NewClient(opts...).All(ctx, family)
 }
 
-// Format retrieves a font face with the specified format and family.
-func Format(ctx context.Context, family, format string, opts ...ClientOption) (Font, error) {
+// Face retrieves a font face with the specified format and family.
+func Face(ctx context.Context, family string, format Format, opts ...ClientOption) (Font, error) {
 	return NewClient(opts...).Format(ctx, family, format)
 }
 
@@ -53,3 +57,9 @@ func WOFF2(ctx context.Context, family string, opts ...ClientOption) (Font, erro
 func WOFF(ctx context.Context, family string, opts ...ClientOption) (Font, error) {
 	return NewClient(opts...).WOFF(ctx, family)
 }
+
+// Download retrieves the font faces for the specified family, downloading each
+// font face to a file in dir.
+func Download(ctx context.Context, family, dir string, opts ...ClientOption) ([]FileInfo, error) {
+	return NewClient(opts...).Download(ctx, family, dir)
+}
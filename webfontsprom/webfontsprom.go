@@ -0,0 +1,112 @@
+// Package webfontsprom provides a Prometheus collector for webfonts client
+// metrics.
+package webfontsprom
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kenshaw/webfonts"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a Prometheus collector for webfonts client metrics. Satisfies
+// both the webfonts.Collector and prometheus.Collector interfaces.
+//
+// Example:
+//
+//	c := webfontsprom.New("")
+//	prometheus.MustRegister(c)
+//	cl := webfonts.NewClient(webfonts.WithMetrics(c))
+type Collector struct {
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	cache       *prometheus.CounterVec
+	downloaded  *prometheus.CounterVec
+	parseErrors prometheus.Counter
+}
+
+// New creates a new Prometheus collector, using the namespace as the metric
+// name prefix (default: webfonts).
+func New(namespace string) *Collector {
+	if namespace == "" {
+		namespace = "webfonts"
+	}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Total upstream requests, by host and status code.",
+		}, []string{"host", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Upstream request duration, by host.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"host"}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_requests_total",
+			Help:      "Total stylesheet and font file retrievals, by kind and cache result (hit, miss).",
+		}, []string{"kind", "result"}),
+		downloaded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "downloaded_bytes_total",
+			Help:      "Total bytes retrieved from the network, by kind.",
+		}, []string{"kind"}),
+		parseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "parse_errors_total",
+			Help:      "Total stylesheet parse failures.",
+		}),
+	}
+}
+
+// Request satisfies the webfonts.Collector interface.
+func (c *Collector) Request(host string, code int, d time.Duration) {
+	c.requests.WithLabelValues(host, strconv.Itoa(code)).Inc()
+	c.duration.WithLabelValues(host).Observe(d.Seconds())
+}
+
+// Cache satisfies the webfonts.Collector interface.
+func (c *Collector) Cache(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	c.cache.WithLabelValues(kind, result).Inc()
+}
+
+// Downloaded satisfies the webfonts.Collector interface.
+func (c *Collector) Downloaded(kind string, n int64) {
+	c.downloaded.WithLabelValues(kind).Add(float64(n))
+}
+
+// ParseError satisfies the webfonts.Collector interface.
+func (c *Collector) ParseError() {
+	c.parseErrors.Inc()
+}
+
+// Describe satisfies the prometheus.Collector interface.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.cache.Describe(ch)
+	c.downloaded.Describe(ch)
+	c.parseErrors.Describe(ch)
+}
+
+// Collect satisfies the prometheus.Collector interface.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.cache.Collect(ch)
+	c.downloaded.Collect(ch)
+	c.parseErrors.Collect(ch)
+}
+
+// ensure the collector satisfies the interfaces.
+var (
+	_ webfonts.Collector   = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
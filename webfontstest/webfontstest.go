@@ -0,0 +1,194 @@
+// Package webfontstest provides an in-memory fake of the Google Fonts
+// Developer API and stylesheet and font file servers, for testing code using
+// webfonts without network access or an API key.
+package webfontstest
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kenshaw/webfonts"
+)
+
+// Fake is an in-memory fake of the Google Fonts Developer API (satisfying the
+// webfonts.Service interface) and the Google Fonts stylesheet and font file
+// servers (satisfying the http.RoundTripper interface), serving canned
+// families, stylesheets, and font files.
+//
+// Example:
+//
+//	f := webfontstest.New()
+//	f.AddFamily(webfonts.Family{Name: "Roboto", Variants: []string{"regular"}})
+//	f.AddStylesheet("Roboto", "", `@font-face {
+//	  font-family: 'Roboto';
+//	  font-style: normal;
+//	  font-weight: 400;
+//	  src: url(https://fonts.gstatic.com/s/roboto/v1/a.woff2) format('woff2');
+//	}`)
+//	f.AddFile("https://fonts.gstatic.com/s/roboto/v1/a.woff2", buf)
+//	cl := f.Client()
+type Fake struct {
+	mu          sync.RWMutex
+	families    []webfonts.Family
+	stylesheets map[string]map[string]string
+	files       map[string][]byte
+}
+
+// New creates a new fake.
+func New() *Fake {
+	return &Fake{
+		stylesheets: make(map[string]map[string]string),
+		files:       make(map[string][]byte),
+	}
+}
+
+// Options returns the client options to use the fake as a client's service
+// and transport, with the default user agent set to webfonts.UserAgentWOFF2.
+func (f *Fake) Options() []webfonts.ClientOption {
+	return []webfonts.ClientOption{
+		webfonts.WithService(f),
+		webfonts.WithTransport(f),
+		webfonts.WithDefaultUserAgent(webfonts.UserAgentWOFF2),
+	}
+}
+
+// Client creates a new webfonts client using the fake (see Options) and
+// passed options.
+func (f *Fake) Client(opts ...webfonts.ClientOption) *webfonts.Client {
+	return webfonts.NewClient(append(f.Options(), opts...)...)
+}
+
+// AddFamily adds families to the fake's available families. Families with
+// the same name are replaced.
+func (f *Fake) AddFamily(families ...webfonts.Family) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, family := range families {
+		i := sort.Search(len(f.families), func(i int) bool {
+			return f.families[i].Name >= family.Name
+		})
+		if i < len(f.families) && f.families[i].Name == family.Name {
+			f.families[i] = family
+			continue
+		}
+		f.families = append(f.families, webfonts.Family{})
+		copy(f.families[i+1:], f.families[i:])
+		f.families[i] = family
+	}
+}
+
+// AddStylesheet adds the stylesheet served for the family when requested
+// with the user agent. An empty user agent adds the stylesheet served when no
+// stylesheet was added for the requesting user agent.
+func (f *Fake) AddStylesheet(family, userAgent, stylesheet string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stylesheets[family] == nil {
+		f.stylesheets[family] = make(map[string]string)
+	}
+	f.stylesheets[family][userAgent] = stylesheet
+}
+
+// AddFile adds a font file served for the url.
+func (f *Fake) AddFile(urlstr string, buf []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[urlstr] = buf
+}
+
+// List satisfies the webfonts.Service interface.
+func (f *Fake) List(ctx context.Context, o *webfonts.ListOptions) ([]webfonts.Family, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	families := make([]webfonts.Family, 0, len(f.families))
+	for _, family := range f.families {
+		switch {
+		case len(o.Families) != 0 && !contains(o.Families, family.Name),
+			o.Subset != "" && !contains(family.Subsets, o.Subset):
+			continue
+		}
+		families = append(families, family)
+	}
+	return families, nil
+}
+
+// RoundTrip satisfies the http.RoundTripper interface. Serves the added
+// stylesheets for css and css2 api requests (see webfonts.ParseQuery), and
+// the added font files for other requests.
+func (f *Fake) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	switch name := path.Base(req.URL.Path); {
+	case name == "css" || name == "css2":
+		q, err := webfonts.ParseQuery(req.URL.String())
+		if err != nil {
+			return response(req, http.StatusBadRequest, "text/plain", []byte(err.Error())), nil
+		}
+		families := q.Families
+		if q.Family != "" {
+			families = []string{q.Family}
+		}
+		var v []string
+		for _, family := range families {
+			m := f.stylesheets[family]
+			stylesheet, ok := m[req.UserAgent()]
+			if !ok {
+				stylesheet, ok = m[""]
+			}
+			if !ok {
+				return response(req, http.StatusBadRequest, "text/plain", []byte("unknown family: "+family)), nil
+			}
+			v = append(v, stylesheet)
+		}
+		return response(req, http.StatusOK, "text/css; charset=utf-8", []byte(strings.Join(v, "\n"))), nil
+	}
+	buf, ok := f.files[req.URL.String()]
+	if !ok {
+		return response(req, http.StatusNotFound, "text/plain", []byte(http.StatusText(http.StatusNotFound))), nil
+	}
+	format, _ := webfonts.ParseFormat(path.Ext(req.URL.Path))
+	return response(req, http.StatusOK, format.ContentType(), buf), nil
+}
+
+// response builds a response for the request.
+func response(req *http.Request, code int, contentType string, buf []byte) *http.Response {
+	return &http.Response{
+		Status:     strconv.Itoa(code) + " " + http.StatusText(code),
+		StatusCode: code,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Content-Type": []string{contentType},
+		},
+		Body:          ioutil.NopCloser(bytes.NewReader(buf)),
+		ContentLength: int64(len(buf)),
+		Request:       req,
+	}
+}
+
+// contains returns true when v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ensure the fake satisfies the interfaces.
+var (
+	_ webfonts.Service  = (*Fake)(nil)
+	_ http.RoundTripper = (*Fake)(nil)
+)
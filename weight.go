@@ -0,0 +1,171 @@
+package webfonts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// weightNames are the css font-weight keywords and common weight names.
+var weightNames = map[string]int{
+	"thin":       100,
+	"hairline":   100,
+	"extralight": 200,
+	"ultralight": 200,
+	"light":      300,
+	"regular":    400,
+	"normal":     400,
+	"medium":     500,
+	"semibold":   600,
+	"demibold":   600,
+	"bold":       700,
+	"extrabold":  800,
+	"ultrabold":  800,
+	"black":      900,
+	"heavy":      900,
+}
+
+// NormalizeWeight normalizes the font weight (ie, "regular", "700", "bold",
+// "semi-bold") to its numeric weight (1-1000).
+func NormalizeWeight(weight string) (int, error) {
+	s := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(weight)))
+	if n, ok := weightNames[s]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || 1000 < n {
+		return 0, fmt.Errorf("%q: %w", weight, ErrInvalidWeight)
+	}
+	return n, nil
+}
+
+// NormalizeVariant normalizes the google variant name (ie, "regular",
+// "italic", "700", "700italic") to its numeric weight and style ("normal" or
+// "italic").
+func NormalizeVariant(variant string) (int, string, error) {
+	s, style := strings.ToLower(strings.TrimSpace(variant)), "normal"
+	if strings.HasSuffix(s, "italic") {
+		s, style = strings.TrimSuffix(s, "italic"), "italic"
+	}
+	if s == "" {
+		return 400, style, nil
+	}
+	weight, err := NormalizeWeight(s)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q: %w", variant, ErrInvalidVariant)
+	}
+	return weight, style, nil
+}
+
+// WeightRange is a font-weight descriptor's numeric weight range. Variable
+// font faces have a weight range (ie, "100 900"), and static font faces have
+// a single weight (ie, "400"), with equal min and max.
+type WeightRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// ParseWeightRange parses a font-weight descriptor value, either a single
+// weight (ie, "400", "bold", see NormalizeWeight) or a variable font weight
+// range (ie, "100 900").
+func ParseWeightRange(s string) (WeightRange, error) {
+	v := strings.Fields(s)
+	switch len(v) {
+	case 1:
+		n, err := NormalizeWeight(v[0])
+		if err != nil {
+			return WeightRange{}, err
+		}
+		return WeightRange{Min: n, Max: n}, nil
+	case 2:
+		min, err := NormalizeWeight(v[0])
+		if err != nil {
+			return WeightRange{}, err
+		}
+		max, err := NormalizeWeight(v[1])
+		if err != nil {
+			return WeightRange{}, err
+		}
+		if max < min {
+			min, max = max, min
+		}
+		return WeightRange{Min: min, Max: max}, nil
+	}
+	return WeightRange{}, fmt.Errorf("%q: %w", s, ErrInvalidWeight)
+}
+
+// Variable returns true when the weight range is a variable font weight range.
+func (wr WeightRange) Variable() bool {
+	return wr.Min != wr.Max
+}
+
+// Contains returns true when the weight range contains the weight.
+func (wr WeightRange) Contains(weight int) bool {
+	return wr.Min <= weight && weight <= wr.Max
+}
+
+// Weights returns the standard weights (100, 200, ..., 900) contained in the
+// weight range, or the single weight of a static weight range.
+func (wr WeightRange) Weights() []int {
+	if !wr.Variable() {
+		return []int{wr.Min}
+	}
+	var v []int
+	for weight := 100; weight <= 900; weight += 100 {
+		if wr.Contains(weight) {
+			v = append(v, weight)
+		}
+	}
+	return v
+}
+
+// String satisfies the fmt.Stringer interface.
+//
+// Returns the weight range as a font-weight descriptor value.
+func (wr WeightRange) String() string {
+	if !wr.Variable() {
+		return strconv.Itoa(wr.Min)
+	}
+	return strconv.Itoa(wr.Min) + " " + strconv.Itoa(wr.Max)
+}
+
+// WeightRange returns the font face's parsed font-weight descriptor.
+func (font Font) WeightRange() (WeightRange, error) {
+	return ParseWeightRange(font.Weight)
+}
+
+// fontVariants returns the google variant names for the font face. Variable
+// font faces return the variant names for each standard weight in the font
+// face's weight range.
+func fontVariants(font Font) []string {
+	wr, err := font.WeightRange()
+	if err != nil || !wr.Variable() {
+		return []string{variantName(font.Weight, font.Style)}
+	}
+	var v []string
+	for _, weight := range wr.Weights() {
+		v = append(v, variantName(strconv.Itoa(weight), font.Style))
+	}
+	return v
+}
+
+// compareWeights compares the font weights (or weight ranges) numerically,
+// ordering invalid weights after valid weights, and comparing invalid weights
+// as strings.
+func compareWeights(a, b string) int {
+	x, errx := ParseWeightRange(a)
+	y, erry := ParseWeightRange(b)
+	switch {
+	case errx != nil && erry != nil:
+		return strings.Compare(a, b)
+	case errx != nil:
+		return 1
+	case erry != nil:
+		return -1
+	case x.Min != y.Min:
+		return x.Min - y.Min
+	case x.Max != y.Max:
+		return x.Max - y.Max
+	}
+	return strings.Compare(a, b)
+}
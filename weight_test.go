@@ -0,0 +1,28 @@
+package webfonts
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCompareWeights(t *testing.T) {
+	weights := []string{"bogus", "1000", "200", "100 900", "abc", "400", "100 900x"}
+	exp := []string{"100 900", "200", "400", "1000", "100 900x", "abc", "bogus"}
+	sort.Slice(weights, func(i, j int) bool {
+		return compareWeights(weights[i], weights[j]) < 0
+	})
+	if !reflect.DeepEqual(weights, exp) {
+		t.Errorf("expected %q, got: %q", exp, weights)
+	}
+	// transitive
+	for _, a := range exp {
+		for _, b := range exp {
+			for _, c := range exp {
+				if compareWeights(a, b) < 0 && compareWeights(b, c) < 0 && compareWeights(a, c) >= 0 {
+					t.Errorf("expected %q < %q", a, c)
+				}
+			}
+		}
+	}
+}